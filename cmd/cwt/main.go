@@ -8,10 +8,30 @@ import (
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/wburton/cwt/internal/manifest"
+	"github.com/wburton/cwt/internal/pty"
+	"github.com/wburton/cwt/internal/sessiond"
 	"github.com/wburton/cwt/internal/tui"
+	"github.com/wburton/cwt/internal/worktree"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "apply" {
+		runApply(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+
+	killOnQuit := false
+	for _, arg := range os.Args[1:] {
+		if arg == "--kill" {
+			killOnQuit = true
+		}
+	}
+
 	// Find git repository root
 	repoRoot, err := findGitRoot()
 	if err != nil {
@@ -28,7 +48,7 @@ func main() {
 	}
 
 	// Create and run TUI
-	model, err := tui.NewModel(repoRoot)
+	model, err := tui.NewModel(repoRoot, killOnQuit)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error initializing: %v\n", err)
 		os.Exit(1)
@@ -41,6 +61,105 @@ func main() {
 	}
 }
 
+// runServe implements `cwt serve`: run the session daemon for a repo in
+// the foreground, owning its agents' PTYs until killed. NewRemoteManager
+// spawns this itself (as a detached background process) the first time a
+// TUI needs a daemon that isn't already running.
+func runServe(args []string) {
+	repoRoot := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--repo" && i+1 < len(args) {
+			repoRoot = args[i+1]
+			i++
+		}
+	}
+	if repoRoot == "" {
+		var err error
+		repoRoot, err = findGitRoot()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	d := sessiond.NewDaemon(repoRoot)
+	if err := d.Serve(sessiond.SocketPath(repoRoot)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running session daemon: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runApply implements `cwt apply <manifest.yaml> [--dry-run]`: spawn every
+// task in a cwt.yaml manifest, or just print the plan cwt would follow.
+func runApply(args []string) {
+	var manifestPath string
+	dryRun := false
+	for _, arg := range args {
+		if arg == "--dry-run" {
+			dryRun = true
+			continue
+		}
+		manifestPath = arg
+	}
+	if manifestPath == "" {
+		manifestPath = "cwt.yaml"
+	}
+
+	mf, err := manifest.Load(manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", manifestPath, err)
+		os.Exit(1)
+	}
+
+	if dryRun {
+		plan, err := mf.Plan()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(plan)
+		return
+	}
+
+	repoRoot, err := findGitRoot()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	wtManager, err := worktree.NewManager(repoRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing: %v\n", err)
+		os.Exit(1)
+	}
+	ptyManager := pty.NewManager()
+
+	agents, err := wtManager.ApplyManifest(mf, func(agent *worktree.Agent, task manifest.Task, onReady func()) error {
+		session, err := ptyManager.SpawnWithLog(agent.ID, agent.Worktree, agent.Task, repoRoot)
+		if err != nil {
+			return err
+		}
+		session.Write([]byte(task.Prompt + "\n"))
+
+		session.SetOutputCallback(func(data []byte) {
+			if strings.Contains(string(data), manifest.SentinelLine) {
+				onReady()
+			}
+		})
+		go func() {
+			<-session.Done()
+			onReady()
+		}()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error applying manifest: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Spawned %d agent(s) from %s\n", len(agents), manifestPath)
+}
+
 // findGitRoot finds the root of the current git repository
 func findGitRoot() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
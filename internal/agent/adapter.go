@@ -0,0 +1,59 @@
+// Package agent defines a pluggable interface for the CLI tool run in each
+// worktree tab, so cwt isn't hardcoded to spawning `claude`.
+package agent
+
+import (
+	"os"
+
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// Adapter adapts one agent CLI (claude, aider, codex, a plain shell, ...)
+// to what a pty.Session needs to run it and interpret its output.
+type Adapter interface {
+	// Name identifies the adapter for the registry, the task@adapter input
+	// shorthand, and the tab bar.
+	Name() string
+	// Command returns the argv and extra environment variables to run this
+	// adapter in worktree for task.
+	Command(worktree, task string) (argv []string, env []string, err error)
+	// DetectReady reports whether output signals the agent is idle and
+	// ready for its next instruction.
+	DetectReady(output []byte) bool
+	// ParseStatus infers a worktree.AgentStatus from an agent's latest
+	// output.
+	ParseStatus(output []byte) worktree.AgentStatus
+}
+
+var registry = map[string]Adapter{}
+
+// Register adds an adapter to the registry, keyed by its Name().
+func Register(a Adapter) {
+	registry[a.Name()] = a
+}
+
+// Get looks up a registered adapter by name.
+func Get(name string) (Adapter, bool) {
+	a, ok := registry[name]
+	return a, ok
+}
+
+// Default returns the configured default adapter — $CWT_ADAPTER if set and
+// registered, else the built-in "claude" adapter — used when Alt+N's task
+// text has no "@adapter" prefix.
+func Default() Adapter {
+	if name := os.Getenv("CWT_ADAPTER"); name != "" {
+		if a, ok := Get(name); ok {
+			return a
+		}
+	}
+	a, _ := Get("claude")
+	return a
+}
+
+func init() {
+	Register(newClaudeAdapter())
+	Register(newAiderAdapter())
+	Register(newCodexAdapter())
+	Register(newShellAdapter())
+}
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"bytes"
+
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// aiderAdapter runs aider (https://aider.chat), a terminal-based AI pair
+// programmer, as a worktree tab's agent.
+type aiderAdapter struct{}
+
+func newAiderAdapter() Adapter { return aiderAdapter{} }
+
+func (aiderAdapter) Name() string { return "aider" }
+
+func (aiderAdapter) Command(_, task string) ([]string, []string, error) {
+	argv := []string{"aider"}
+	if task != "" {
+		argv = append(argv, "--message", task)
+	}
+	return argv, nil, nil
+}
+
+// aider drops back to its own "> " prompt between turns.
+func (aiderAdapter) DetectReady(output []byte) bool {
+	return bytes.Contains(output, []byte("\n> "))
+}
+
+func (aiderAdapter) ParseStatus(output []byte) worktree.AgentStatus {
+	if bytes.Contains(output, []byte("\n> ")) {
+		return worktree.StatusCompleted
+	}
+	return worktree.StatusRunning
+}
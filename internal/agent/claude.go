@@ -0,0 +1,31 @@
+package agent
+
+import (
+	"bytes"
+
+	"github.com/wburton/cwt/internal/manifest"
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// claudeAdapter runs Claude Code itself — cwt's original, hardcoded
+// behavior, and the default adapter.
+type claudeAdapter struct{}
+
+func newClaudeAdapter() Adapter { return claudeAdapter{} }
+
+func (claudeAdapter) Name() string { return "claude" }
+
+func (claudeAdapter) Command(_, _ string) ([]string, []string, error) {
+	return []string{"claude"}, nil, nil
+}
+
+func (claudeAdapter) DetectReady(output []byte) bool {
+	return bytes.Contains(output, []byte(manifest.SentinelLine))
+}
+
+func (claudeAdapter) ParseStatus(output []byte) worktree.AgentStatus {
+	if bytes.Contains(output, []byte(manifest.SentinelLine)) {
+		return worktree.StatusCompleted
+	}
+	return worktree.StatusRunning
+}
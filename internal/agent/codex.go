@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"bytes"
+
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// codexAdapter runs OpenAI's codex CLI as a worktree tab's agent.
+type codexAdapter struct{}
+
+func newCodexAdapter() Adapter { return codexAdapter{} }
+
+func (codexAdapter) Name() string { return "codex" }
+
+func (codexAdapter) Command(_, task string) ([]string, []string, error) {
+	argv := []string{"codex"}
+	if task != "" {
+		argv = append(argv, task)
+	}
+	return argv, nil, nil
+}
+
+func (codexAdapter) DetectReady(output []byte) bool {
+	return bytes.Contains(output, []byte("\n› "))
+}
+
+func (codexAdapter) ParseStatus(output []byte) worktree.AgentStatus {
+	if bytes.Contains(output, []byte("\n› ")) {
+		return worktree.StatusCompleted
+	}
+	return worktree.StatusRunning
+}
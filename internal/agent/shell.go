@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"os"
+
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// shellAdapter runs a plain interactive shell instead of any AI agent, for
+// manual work inside a worktree tab.
+type shellAdapter struct{}
+
+func newShellAdapter() Adapter { return shellAdapter{} }
+
+func (shellAdapter) Name() string { return "shell" }
+
+func (shellAdapter) Command(_, _ string) ([]string, []string, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return []string{shell}, nil, nil
+}
+
+// A shell has no turn-based notion of "done"; it's always running.
+func (shellAdapter) DetectReady(output []byte) bool { return false }
+
+func (shellAdapter) ParseStatus(output []byte) worktree.AgentStatus {
+	return worktree.StatusRunning
+}
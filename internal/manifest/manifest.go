@@ -0,0 +1,177 @@
+// Package manifest loads cwt.yaml task manifests, which let a user declare
+// a set of agents to spawn in one shot instead of driving each Alt+N
+// prompt by hand.
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SentinelLine is the line a task's agent can print to signal it's done,
+// as an alternative to Manager watching for process exit.
+const SentinelLine = "CWT_TASK_DONE"
+
+// MergePolicy controls whether/how a task's branch merges automatically
+// once its agent finishes.
+type MergePolicy struct {
+	Auto     bool   `yaml:"auto"`
+	Strategy string `yaml:"strategy"` // merge | rebase | squash
+}
+
+// Task describes one agent to spawn as part of a Manifest.
+type Task struct {
+	Name      string            `yaml:"name"`
+	Prompt    string            `yaml:"prompt"`
+	Base      string            `yaml:"base"` // a branch name, or another task's Name to chain off
+	Labels    map[string]string `yaml:"labels,omitempty"`
+	DependsOn []string          `yaml:"depends_on,omitempty"`
+	Merge     MergePolicy       `yaml:"merge,omitempty"`
+}
+
+// Manifest is the parsed contents of a cwt.yaml task manifest.
+type Manifest struct {
+	Tasks []Task `yaml:"tasks"`
+}
+
+// Load reads and parses a manifest file.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Validate checks task name uniqueness and that depends_on references
+// only tasks that exist in the manifest.
+func (m *Manifest) Validate() error {
+	names := make(map[string]bool, len(m.Tasks))
+	for _, t := range m.Tasks {
+		if t.Name == "" {
+			return fmt.Errorf("task missing name")
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate task name %q", t.Name)
+		}
+		names[t.Name] = true
+	}
+	for _, t := range m.Tasks {
+		for _, dep := range t.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("task %q depends_on unknown task %q", t.Name, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// TopoSort returns tasks ordered so each task appears after every task in
+// its DependsOn and, if Base names another task, after that task too.
+func (m *Manifest) TopoSort() ([]Task, error) {
+	byName := make(map[string]Task, len(m.Tasks))
+	for _, t := range m.Tasks {
+		byName[t.Name] = t
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(m.Tasks))
+	var order []Task
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle at task %q", name)
+		}
+		state[name] = visiting
+
+		t, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown task %q", name)
+		}
+		for _, dep := range t.DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		if t.Base != "" && t.Base != name {
+			if _, isTask := byName[t.Base]; isTask {
+				if err := visit(t.Base); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[name] = done
+		order = append(order, t)
+		return nil
+	}
+
+	for _, t := range m.Tasks {
+		if err := visit(t.Name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Plan renders a human-readable preview of the order tasks would be
+// created in, for a --dry-run.
+func (m *Manifest) Plan() (string, error) {
+	order, err := m.TopoSort()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, t := range order {
+		fmt.Fprintf(&b, "%d. %s", i+1, t.Name)
+		if t.Base != "" {
+			fmt.Fprintf(&b, " (base: %s)", t.Base)
+		}
+		if len(t.DependsOn) > 0 {
+			fmt.Fprintf(&b, " (depends_on: %s)", strings.Join(t.DependsOn, ", "))
+		}
+		if len(t.Labels) > 0 {
+			fmt.Fprintf(&b, " [%s]", formatLabels(t.Labels))
+		}
+		if t.Merge.Auto {
+			fmt.Fprintf(&b, " (auto-merge: %s)", t.Merge.Strategy)
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, labels[k]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,38 @@
+package manifest
+
+import "testing"
+
+func TestTopoSortOrdersDependencies(t *testing.T) {
+	m := &Manifest{Tasks: []Task{
+		{Name: "c", DependsOn: []string{"b"}},
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a"}},
+	}}
+
+	order, err := m.TopoSort()
+	if err != nil {
+		t.Fatalf("TopoSort returned error: %v", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, task := range order {
+		pos[task.Name] = i
+	}
+	if pos["a"] > pos["b"] {
+		t.Errorf("expected %q before %q, got order %v", "a", "b", order)
+	}
+	if pos["b"] > pos["c"] {
+		t.Errorf("expected %q before %q, got order %v", "b", "c", order)
+	}
+}
+
+func TestTopoSortDetectsCycle(t *testing.T) {
+	m := &Manifest{Tasks: []Task{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	}}
+
+	if _, err := m.TopoSort(); err == nil {
+		t.Fatal("expected an error for a dependency cycle, got nil")
+	}
+}
@@ -2,6 +2,8 @@ package pty
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 )
 
@@ -9,15 +11,32 @@ import (
 type Manager struct {
 	sessions map[string]*Session
 	mu       sync.RWMutex
+	remote   *remoteConn
 }
 
-// NewManager creates a new PTY manager
+// NewManager creates a new PTY manager that execs and owns its PTYs
+// directly in this process.
 func NewManager() *Manager {
 	return &Manager{
 		sessions: make(map[string]*Session),
 	}
 }
 
+// NewRemoteManager connects to the session daemon for repoRoot, starting
+// one in the background (via `cwt serve`) if it isn't already running.
+// Its Sessions are thin proxies: the daemon owns the real PTYs, so their
+// scrollback and running agents survive this process exiting or crashing.
+func NewRemoteManager(repoRoot string) (*Manager, error) {
+	conn, err := ensureDaemon(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("connect to session daemon: %w", err)
+	}
+	return &Manager{
+		sessions: make(map[string]*Session),
+		remote:   conn,
+	}, nil
+}
+
 // Spawn creates and starts a new PTY session
 func (m *Manager) Spawn(id, workdir, task string) (*Session, error) {
 	m.mu.Lock()
@@ -36,6 +55,245 @@ func (m *Manager) Spawn(id, workdir, task string) (*Session, error) {
 	return session, nil
 }
 
+// SpawnWithLog creates and starts a new PTY session wired to an append-only
+// operation log rooted at repoRoot, so its output survives process exit and
+// can be replayed on a later resume or fork.
+func (m *Manager) SpawnWithLog(id, workdir, task, repoRoot string) (*Session, error) {
+	if m.remote != nil {
+		return m.spawnRemote(id, workdir, task, nil, nil)
+	}
+
+	log, err := OpenSessionLog(repoRoot, id)
+	if err != nil {
+		return nil, fmt.Errorf("open session log: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.SetLog(log)
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// SpawnAdapter is SpawnWithLog for a non-default agent.Adapter: it runs
+// argv/env instead of the hardcoded `claude` invocation.
+func (m *Manager) SpawnAdapter(id, workdir, task, repoRoot string, argv, env []string) (*Session, error) {
+	if m.remote != nil {
+		return m.spawnRemote(id, workdir, task, argv, env)
+	}
+
+	log, err := OpenSessionLog(repoRoot, id)
+	if err != nil {
+		return nil, fmt.Errorf("open session log: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.SetCommand(argv, env)
+	session.SetLog(log)
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// ScanResumable lists agent IDs under repoRoot that have an operation log,
+// regardless of whether a PTY for them is currently running.
+func ScanResumable(repoRoot string) ([]string, error) {
+	dir := filepath.Join(repoRoot, ".cwt", "sessions")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	return ids, nil
+}
+
+// Resume re-attaches a fresh PTY process to an existing operation log,
+// seeding the session's ring buffer with the last-known screen (replayed
+// through a vt10x emulator) so the TUI shows where the agent left off.
+func (m *Manager) Resume(id, workdir, task, repoRoot string, rows, cols int) (*Session, error) {
+	if m.remote != nil {
+		return m.attachRemote(id, workdir, task, rows, cols, nil, nil)
+	}
+
+	log, err := OpenSessionLog(repoRoot, id)
+	if err != nil {
+		return nil, fmt.Errorf("open session log: %w", err)
+	}
+
+	ops, err := log.ReadSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("read session log: %w", err)
+	}
+	screen := replayScreen(ops, rows, cols)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.buffer.Write(screen)
+	session.SetLog(log)
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to resume session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// ResumeAdapter is Resume for an agent whose Tab was running a non-default
+// agent.Adapter, so the re-attached PTY execs the same command instead of
+// falling back to `claude`.
+func (m *Manager) ResumeAdapter(id, workdir, task, repoRoot string, rows, cols int, argv, env []string) (*Session, error) {
+	if m.remote != nil {
+		return m.attachRemote(id, workdir, task, rows, cols, argv, env)
+	}
+
+	log, err := OpenSessionLog(repoRoot, id)
+	if err != nil {
+		return nil, fmt.Errorf("open session log: %w", err)
+	}
+
+	ops, err := log.ReadSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("read session log: %w", err)
+	}
+	screen := replayScreen(ops, rows, cols)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.buffer.Write(screen)
+	session.SetCommand(argv, env)
+	session.SetLog(log)
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to resume session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// spawnRemote is SpawnWithLog/SpawnAdapter for a remote Manager: it asks
+// the session daemon to spawn a brand-new PTY instead of exec'ing one here.
+func (m *Manager) spawnRemote(id, workdir, task string, argv, env []string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.remote = &remoteLink{c: m.remote, id: id, op: "spawn", argv: argv, env: env}
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// attachRemote is Resume/ResumeAdapter for a remote Manager: it asks the
+// session daemon to attach to id's PTY if it's still running there, or
+// revive it from its operation log otherwise, instead of doing either
+// locally.
+func (m *Manager) attachRemote(id, workdir, task string, rows, cols int, argv, env []string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[id]; exists {
+		return nil, fmt.Errorf("session %s already exists", id)
+	}
+
+	session := NewSession(id, workdir, task)
+	session.remote = &remoteLink{c: m.remote, id: id, op: "attach", argv: argv, env: env, rows: rows, cols: cols}
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to attach session: %w", err)
+	}
+
+	m.sessions[id] = session
+	return session, nil
+}
+
+// Fork replays forkID's log into a brand-new session under newID without
+// reusing the original log, so the new session starts from the same
+// on-screen state but accumulates its own independent history from here.
+func (m *Manager) Fork(newID, forkID, workdir, task, repoRoot string, rows, cols int) (*Session, error) {
+	if m.remote != nil {
+		return nil, fmt.Errorf("fork is not yet supported against a session daemon")
+	}
+
+	srcLog, err := OpenSessionLog(repoRoot, forkID)
+	if err != nil {
+		return nil, fmt.Errorf("open source session log: %w", err)
+	}
+	ops, err := srcLog.ReadSince(0)
+	if err != nil {
+		return nil, fmt.Errorf("read source session log: %w", err)
+	}
+	screen := replayScreen(ops, rows, cols)
+
+	dstLog, err := OpenSessionLog(repoRoot, newID)
+	if err != nil {
+		return nil, fmt.Errorf("open forked session log: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.sessions[newID]; exists {
+		return nil, fmt.Errorf("session %s already exists", newID)
+	}
+
+	session := NewSession(newID, workdir, task)
+	session.buffer.Write(screen)
+	session.SetLog(dstLog)
+	if err := session.Start(); err != nil {
+		return nil, fmt.Errorf("failed to fork session: %w", err)
+	}
+
+	m.sessions[newID] = session
+	return session, nil
+}
+
 // Get returns a session by ID
 func (m *Manager) Get(id string) (*Session, bool) {
 	m.mu.RLock()
@@ -83,23 +341,27 @@ func (m *Manager) Write(id string, data []byte) (int, error) {
 	return session.Write(data)
 }
 
-// ResizeAll resizes all sessions
-func (m *Manager) ResizeAll(rows, cols int) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+// StopAll stops all sessions
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	for _, session := range m.sessions {
-		session.Resize(rows, cols)
+	for id, session := range m.sessions {
+		session.Stop()
+		delete(m.sessions, id)
 	}
 }
 
-// StopAll stops all sessions
-func (m *Manager) StopAll() {
+// DetachAll disconnects every session without ending it, so a session
+// daemon keeps each one running for a later Resume/ResumeAdapter instead of
+// losing them when this process exits. Sessions with no daemon behind them
+// have nothing to keep them alive, so for those this still stops them.
+func (m *Manager) DetachAll() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	for id, session := range m.sessions {
-		session.Stop()
+		session.Detach()
 		delete(m.sessions, id)
 	}
 }
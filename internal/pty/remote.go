@@ -0,0 +1,169 @@
+package pty
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// remoteRequest and remoteResponse mirror internal/sessiond's wire
+// protocol (sessiond.Request/Response) field-for-field. They're duplicated
+// here rather than imported so internal/pty and internal/sessiond don't
+// form an import cycle: the daemon imports pty to actually drive PTYs, and
+// this client only ever talks to it as JSON over a socket, never through a
+// shared Go type.
+type remoteRequest struct {
+	Op      string   `json:"op"`
+	ID      string   `json:"id"`
+	Workdir string   `json:"workdir,omitempty"`
+	Task    string   `json:"task,omitempty"`
+	Argv    []string `json:"argv,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Data    []byte   `json:"data,omitempty"`
+	Rows    int      `json:"rows,omitempty"`
+	Cols    int      `json:"cols,omitempty"`
+	Since   uint64   `json:"since,omitempty"`
+}
+
+type remoteResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Data  []byte `json:"data,omitempty"`
+	Seq   uint64 `json:"seq,omitempty"`
+}
+
+// SocketPath returns the Unix socket a cwt session daemon listens on for
+// repoRoot. Must match sessiond.SocketPath.
+func SocketPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".cwt", "sessiond.sock")
+}
+
+// remoteConn is a connection to a session daemon, shared by every
+// remote-backed Session a single Manager creates.
+type remoteConn struct {
+	mu  sync.Mutex
+	c   net.Conn
+	sc  *bufio.Scanner
+	enc *json.Encoder
+}
+
+func dialDaemon(socketPath string) (*remoteConn, error) {
+	c, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := bufio.NewScanner(c)
+	sc.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	return &remoteConn{c: c, sc: sc, enc: json.NewEncoder(c)}, nil
+}
+
+// ensureDaemon dials repoRoot's session daemon, starting one in the
+// background via `cwt serve` first if it isn't already running.
+func ensureDaemon(repoRoot string) (*remoteConn, error) {
+	socketPath := SocketPath(repoRoot)
+
+	if conn, err := dialDaemon(socketPath); err == nil {
+		return conn, nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("locate cwt binary: %w", err)
+	}
+
+	cmd := exec.Command(self, "serve", "--repo", repoRoot)
+	cmd.Dir = repoRoot
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start session daemon: %w", err)
+	}
+
+	var lastErr error
+	for i := 0; i < 50; i++ {
+		time.Sleep(100 * time.Millisecond)
+		conn, err := dialDaemon(socketPath)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("session daemon did not come up: %w", lastErr)
+}
+
+func (rc *remoteConn) roundTrip(req remoteRequest) (remoteResponse, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if err := rc.enc.Encode(req); err != nil {
+		return remoteResponse{}, fmt.Errorf("send request: %w", err)
+	}
+	if !rc.sc.Scan() {
+		if err := rc.sc.Err(); err != nil {
+			return remoteResponse{}, fmt.Errorf("read response: %w", err)
+		}
+		return remoteResponse{}, fmt.Errorf("session daemon closed the connection")
+	}
+
+	var resp remoteResponse
+	if err := json.Unmarshal(rc.sc.Bytes(), &resp); err != nil {
+		return remoteResponse{}, fmt.Errorf("decode response: %w", err)
+	}
+	if !resp.OK {
+		return remoteResponse{}, fmt.Errorf("%s", resp.Error)
+	}
+	return resp, nil
+}
+
+func (rc *remoteConn) spawn(id, workdir, task string, argv, env []string) ([]byte, uint64, error) {
+	resp, err := rc.roundTrip(remoteRequest{Op: "spawn", ID: id, Workdir: workdir, Task: task, Argv: argv, Env: env})
+	return resp.Data, resp.Seq, err
+}
+
+func (rc *remoteConn) attach(id, workdir, task string, rows, cols int, argv, env []string) ([]byte, uint64, error) {
+	resp, err := rc.roundTrip(remoteRequest{
+		Op: "attach", ID: id, Workdir: workdir, Task: task,
+		Rows: rows, Cols: cols, Argv: argv, Env: env,
+	})
+	return resp.Data, resp.Seq, err
+}
+
+func (rc *remoteConn) write(id string, data []byte) error {
+	_, err := rc.roundTrip(remoteRequest{Op: "write", ID: id, Data: data})
+	return err
+}
+
+func (rc *remoteConn) resize(id string, rows, cols int) error {
+	_, err := rc.roundTrip(remoteRequest{Op: "resize", ID: id, Rows: rows, Cols: cols})
+	return err
+}
+
+func (rc *remoteConn) kill(id string) error {
+	_, err := rc.roundTrip(remoteRequest{Op: "kill", ID: id})
+	return err
+}
+
+func (rc *remoteConn) outputSince(id string, since uint64) ([]byte, uint64, error) {
+	resp, err := rc.roundTrip(remoteRequest{Op: "output_since", ID: id, Since: since})
+	return resp.Data, resp.Seq, err
+}
+
+// remoteLink makes a Session a thin proxy to a session daemon instead of a
+// locally exec'd PTY: see Session.startRemote.
+type remoteLink struct {
+	c    *remoteConn
+	id   string
+	op   string // "spawn" or "attach"
+	argv []string
+	env  []string
+	rows int
+	cols int
+
+	lastSeq uint64
+	stop    chan struct{}
+}
@@ -0,0 +1,98 @@
+package pty
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hinshun/vt10x"
+)
+
+// replayScreen feeds the stdout frames of an operation log through a
+// headless vt10x terminal and serializes its current screen back into raw
+// ANSI (cursor-ordered text with SGR codes), so a resumed or forked session
+// shows the agent's last-known output, colors and all, instead of a blank
+// pane. Kept in its own file so the vt10x API surface we depend on stays
+// narrow.
+func replayScreen(ops []Op, rows, cols int) []byte {
+	term := vt10x.New(vt10x.WithSize(cols, rows))
+
+	for _, op := range ops {
+		if op.Kind != OpStdout {
+			continue
+		}
+		term.Write(op.Payload)
+	}
+
+	return screenANSI(term, cols, rows)
+}
+
+// screenANSI renders term's current grid as raw ANSI: one row per line, SGR
+// codes emitted only where the style actually changes. The result is meant
+// to be fed straight into a fresh vterm.Terminal, so it must be real ANSI,
+// not vt10x's own String() (bare runes, NUL-padded blanks, no attributes).
+func screenANSI(term vt10x.Terminal, cols, rows int) []byte {
+	term.Lock()
+	defer term.Unlock()
+
+	var b strings.Builder
+	for y := 0; y < rows; y++ {
+		if y > 0 {
+			b.WriteString("\r\n")
+		}
+		var cur vt10x.Glyph
+		have := false
+		for x := 0; x < cols; x++ {
+			g := term.Cell(x, y)
+			if !have || g.Mode != cur.Mode || g.FG != cur.FG || g.BG != cur.BG {
+				b.WriteString(sgr(g))
+				cur, have = g, true
+			}
+			ch := g.Char
+			if ch == 0 {
+				ch = ' '
+			}
+			b.WriteRune(ch)
+		}
+	}
+	b.WriteString("\x1b[0m")
+	return []byte(b.String())
+}
+
+// Glyph.Mode bits below mirror vt10x's own (unexported) attrReverse/
+// attrUnderline/attrBold layout for the vt10x version this module is
+// pinned to; vt10x doesn't expose them itself, only the Mode int16.
+const (
+	attrReverse = 1 << iota
+	attrUnderline
+	attrBold
+)
+
+func sgr(g vt10x.Glyph) string {
+	codes := []string{"0"}
+	if g.Mode&attrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if g.Mode&attrUnderline != 0 {
+		codes = append(codes, "4")
+	}
+	if g.Mode&attrReverse != 0 {
+		codes = append(codes, "7")
+	}
+	if g.FG.ANSI() {
+		codes = append(codes, colorCode(g.FG, 30, 90))
+	}
+	if g.BG.ANSI() {
+		codes = append(codes, colorCode(g.BG, 40, 100))
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+// colorCode renders an ANSI color index with the base offset for [0,8) and
+// the bright offset for [8,16).
+func colorCode(c vt10x.Color, base, bright int) string {
+	n := int(c)
+	if n < 8 {
+		return strconv.Itoa(base + n)
+	}
+	return strconv.Itoa(bright + n - 8)
+}
@@ -1,10 +1,12 @@
 package pty
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"sync"
+	"time"
 
 	"github.com/creack/pty"
 )
@@ -20,6 +22,10 @@ type Session struct {
 	mu       sync.RWMutex
 	done     chan struct{}
 	onOutput func([]byte)
+	log      *SessionLog
+	argv     []string
+	env      []string
+	remote   *remoteLink
 }
 
 // RingBuffer is a fixed-size circular buffer for terminal output
@@ -84,14 +90,39 @@ func NewSession(id, workdir, task string) *Session {
 	}
 }
 
-// Start spawns the claude command in a PTY
+// SetCommand overrides the command this session's PTY execs, letting a
+// pluggable agent.Adapter (claude, aider, codex, a plain shell, ...) run
+// instead of the default `claude` invocation. Must be called before Start.
+func (s *Session) SetCommand(argv, env []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.argv = argv
+	s.env = env
+}
+
+// Start spawns the session's command (claude by default, or whatever
+// SetCommand set) in a PTY, or, for a remote-backed Session, asks the
+// session daemon to do so instead. See startRemote.
 func (s *Session) Start() error {
+	s.mu.RLock()
+	link := s.remote
+	s.mu.RUnlock()
+	if link != nil {
+		return s.startRemote(link)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	cmd := exec.Command("claude")
+	argv := s.argv
+	if len(argv) == 0 {
+		argv = []string{"claude"}
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
 	cmd.Dir = s.Workdir
 	cmd.Env = append(os.Environ(), "TERM=xterm-256color")
+	cmd.Env = append(cmd.Env, s.env...)
 
 	ptmx, err := pty.Start(cmd)
 	if err != nil {
@@ -122,6 +153,9 @@ func (s *Session) readLoop() {
 		}
 		if n > 0 {
 			s.buffer.Write(buf[:n])
+			if s.log != nil {
+				s.log.Append(OpStdout, append([]byte(nil), buf[:n]...))
+			}
 			if s.onOutput != nil {
 				s.onOutput(buf[:n])
 			}
@@ -129,14 +163,81 @@ func (s *Session) readLoop() {
 	}
 }
 
-// Write sends input to the PTY
+// startRemote asks the session daemon behind link to spawn or attach to
+// link.id, seeds the buffer with whatever screen it hands back, and starts
+// polling it for new output in place of a local readLoop.
+func (s *Session) startRemote(link *remoteLink) error {
+	var (
+		data []byte
+		seq  uint64
+		err  error
+	)
+	switch link.op {
+	case "attach":
+		data, seq, err = link.c.attach(link.id, s.Workdir, s.Task, link.rows, link.cols, link.argv, link.env)
+	default:
+		data, seq, err = link.c.spawn(link.id, s.Workdir, s.Task, link.argv, link.env)
+	}
+	if err != nil {
+		return err
+	}
+
+	link.lastSeq = seq
+	link.stop = make(chan struct{})
+	s.buffer.Write(data)
+	go s.pollRemote(link)
+	return nil
+}
+
+// pollRemote periodically fetches output the daemon has produced since
+// link.lastSeq, the client side of the output-since op in the daemon's
+// protocol. It exits when the session is stopped or detached.
+func (s *Session) pollRemote(link *remoteLink) {
+	ticker := time.NewTicker(150 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-link.stop:
+			return
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		data, seq, err := link.c.outputSince(link.id, link.lastSeq)
+		if err != nil {
+			return
+		}
+		link.lastSeq = seq
+		if len(data) == 0 {
+			continue
+		}
+
+		s.buffer.Write(data)
+		if s.onOutput != nil {
+			s.onOutput(data)
+		}
+	}
+}
+
+// Write sends input to the PTY, or to the daemon-owned PTY for a remote
+// session.
 func (s *Session) Write(data []byte) (int, error) {
 	s.mu.RLock()
+	link := s.remote
 	defer s.mu.RUnlock()
 
+	if link != nil {
+		return len(data), link.c.write(link.id, data)
+	}
+
 	if s.pty == nil {
 		return 0, io.ErrClosedPipe
 	}
+	if s.log != nil {
+		s.log.Append(OpStdin, data)
+	}
 	return s.pty.Write(data)
 }
 
@@ -152,25 +253,59 @@ func (s *Session) SetOutputCallback(cb func([]byte)) {
 	s.onOutput = cb
 }
 
-// Resize resizes the PTY
+// SetLog wires an append-only operation log into the session. Must be
+// called before Start; every byte written to or read from the PTY after
+// that is also appended to the log.
+func (s *Session) SetLog(log *SessionLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = log
+}
+
+// Resize resizes the PTY, or the daemon-owned PTY for a remote session.
 func (s *Session) Resize(rows, cols uint16) error {
 	s.mu.RLock()
+	link := s.remote
 	defer s.mu.RUnlock()
 
+	if link != nil {
+		return link.c.resize(link.id, int(rows), int(cols))
+	}
+
 	if s.pty == nil {
 		return nil
 	}
+	if s.log != nil {
+		s.log.Append(OpResize, []byte(fmt.Sprintf("%dx%d", rows, cols)))
+	}
 	return pty.Setsize(s.pty, &pty.Winsize{
 		Rows: rows,
 		Cols: cols,
 	})
 }
 
-// Stop terminates the session
+// Stop terminates the session. For a remote session this tells the daemon
+// to kill the underlying PTY outright; use Detach to stop watching it
+// without ending the agent.
 func (s *Session) Stop() error {
+	s.mu.Lock()
+	link := s.remote
+	s.mu.Unlock()
+
+	if link != nil {
+		close(link.stop)
+		err := link.c.kill(link.id)
+		s.closeDone()
+		return err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if s.log != nil {
+		s.log.Append(OpExit, nil)
+		s.log.Close()
+	}
 	if s.pty != nil {
 		s.pty.Close()
 	}
@@ -180,6 +315,31 @@ func (s *Session) Stop() error {
 	return nil
 }
 
+// Detach stops polling a remote session for output without telling the
+// daemon to kill it, so the agent keeps running in the background and a
+// later Resume/ResumeAdapter can pick it back up. A local (non-daemon-
+// backed) session has nothing to keep it alive once nobody's watching, so
+// Detach just stops it instead.
+func (s *Session) Detach() error {
+	s.mu.RLock()
+	link := s.remote
+	s.mu.RUnlock()
+
+	if link == nil {
+		return s.Stop()
+	}
+	close(link.stop)
+	return nil
+}
+
+func (s *Session) closeDone() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+}
+
 // Done returns a channel that closes when the session ends
 func (s *Session) Done() <-chan struct{} {
 	return s.done
@@ -194,3 +354,44 @@ func (s *Session) IsRunning() bool {
 		return true
 	}
 }
+
+// LogSeq returns the session's operation log's next sequence number, i.e.
+// the cursor a caller should start an output-since poll from to see only
+// output produced after this point. Sessions with no log return 0.
+func (s *Session) LogSeq() uint64 {
+	s.mu.RLock()
+	log := s.log
+	s.mu.RUnlock()
+	if log == nil {
+		return 0
+	}
+	return log.Seq()
+}
+
+// LogOpsSince returns this session's logged stdout bytes with Seq >= since,
+// concatenated in order, plus the seq a caller should pass to the next
+// call to pick up where this one left off. Used by the session daemon to
+// serve output-since requests without re-replaying the whole log.
+func (s *Session) LogOpsSince(since uint64) ([]byte, uint64, error) {
+	s.mu.RLock()
+	log := s.log
+	s.mu.RUnlock()
+	if log == nil {
+		return nil, since, nil
+	}
+
+	ops, err := log.ReadSince(since)
+	if err != nil {
+		return nil, since, err
+	}
+
+	var out []byte
+	next := since
+	for _, op := range ops {
+		if op.Kind == OpStdout {
+			out = append(out, op.Payload...)
+		}
+		next = op.Seq + 1
+	}
+	return out, next, nil
+}
@@ -0,0 +1,382 @@
+package pty
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpKind identifies the kind of frame recorded in a SessionLog.
+type OpKind string
+
+const (
+	OpStdin  OpKind = "stdin"
+	OpStdout OpKind = "stdout"
+	OpResize OpKind = "resize"
+	OpExit   OpKind = "exit"
+)
+
+// Op is one append-only, content-addressed record in a session's
+// operation log. Hash chains to PrevHash the way git-bug chains its
+// operation packs, so SessionLog.Verify can detect tampering or a
+// truncated write.
+type Op struct {
+	Seq       uint64    `json:"seq"`
+	Timestamp time.Time `json:"ts"`
+	Kind      OpKind    `json:"kind"`
+	Payload   []byte    `json:"payload"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+}
+
+func hashOp(seq uint64, ts time.Time, kind OpKind, payload []byte, prevHash string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%d|%s|%s|", seq, ts.UnixNano(), kind, prevHash)
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// segmentMaxBytes rotates to a new segment file once the active one grows
+// past this size, so CompactBefore can drop whole old segments cheaply
+// instead of rewriting one ever-growing file.
+const segmentMaxBytes = 1 << 20 // 1MiB
+
+// syncInterval bounds how long a frame can sit unflushed: Append batches
+// writes into the active segment and only fsyncs when this much time has
+// passed since the last sync, or the segment hits segmentMaxBytes.
+// Interactive PTY output arrives in frequent, often tiny chunks, so
+// fsyncing every single one would make ordinary use painfully slow; this
+// caps the durability window instead of giving it up.
+const syncInterval = 200 * time.Millisecond
+
+// SessionLog is an append-only, content-addressed record of everything
+// sent to and received from a PTY session, stored as rotating segment
+// files (one JSON object per line) under
+// <repoRoot>/.cwt/sessions/<agentID>/ops/. It survives process exit, so a
+// restarted cwt can replay it to show where a session left off.
+type SessionLog struct {
+	dir string
+
+	mu        sync.Mutex
+	seq       uint64
+	lastHash  string
+	file      *os.File
+	fileFirst uint64 // seq of the first op written to file
+	fileBytes int64
+	lastSync  time.Time
+}
+
+// OpsDir returns the directory an agent's operation log is stored under.
+func OpsDir(repoRoot, agentID string) string {
+	return filepath.Join(repoRoot, ".cwt", "sessions", agentID, "ops")
+}
+
+// OpenSessionLog opens (creating if needed) the operation log for agentID,
+// picking up the seq/hash chain where a previous run left off, and starts
+// a fresh segment for this run to append to.
+func OpenSessionLog(repoRoot, agentID string) (*SessionLog, error) {
+	dir := OpsDir(repoRoot, agentID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create ops dir: %w", err)
+	}
+
+	l := &SessionLog{dir: dir}
+	ops, err := l.readAll()
+	if err != nil {
+		return nil, err
+	}
+	if n := len(ops); n > 0 {
+		last := ops[n-1]
+		l.seq = last.Seq + 1
+		l.lastHash = last.Hash
+	}
+	if err := l.openSegment(l.seq); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *SessionLog) segPath(firstSeq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d.jsonl", firstSeq))
+}
+
+// openSegment starts a new segment file for writes beginning at firstSeq,
+// closing whatever segment was previously open.
+func (l *SessionLog) openSegment(firstSeq uint64) error {
+	if l.file != nil {
+		l.file.Close()
+	}
+	f, err := os.OpenFile(l.segPath(firstSeq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("open segment: %w", err)
+	}
+	l.file = f
+	l.fileFirst = firstSeq
+	l.fileBytes = 0
+	l.lastSync = time.Now()
+	return nil
+}
+
+// Seq returns the next sequence number Append will use, i.e. one past the
+// last op currently in the log.
+func (l *SessionLog) Seq() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// Append writes a new frame to the active segment. It only fsyncs (and
+// rotates to a new segment, once the active one is large enough) on a
+// size/time schedule rather than on every call, since readLoop can call
+// this for every few bytes of interactive PTY output.
+func (l *SessionLog) Append(kind OpKind, payload []byte) (Op, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	op := Op{
+		Seq:       l.seq,
+		Timestamp: time.Now(),
+		Kind:      kind,
+		Payload:   payload,
+		PrevHash:  l.lastHash,
+	}
+	op.Hash = hashOp(op.Seq, op.Timestamp, op.Kind, op.Payload, op.PrevHash)
+
+	data, err := json.Marshal(op)
+	if err != nil {
+		return Op{}, err
+	}
+	data = append(data, '\n')
+
+	if l.file == nil {
+		if err := l.openSegment(op.Seq); err != nil {
+			return Op{}, err
+		}
+	}
+
+	n, err := l.file.Write(data)
+	if err != nil {
+		return Op{}, err
+	}
+	l.fileBytes += int64(n)
+
+	if l.fileBytes >= segmentMaxBytes {
+		if err := l.file.Sync(); err != nil {
+			return Op{}, err
+		}
+		if err := l.openSegment(op.Seq + 1); err != nil {
+			return Op{}, err
+		}
+	} else if time.Since(l.lastSync) >= syncInterval {
+		if err := l.file.Sync(); err != nil {
+			return Op{}, err
+		}
+		l.lastSync = time.Now()
+	}
+
+	l.seq++
+	l.lastHash = op.Hash
+	return op, nil
+}
+
+// Close flushes and closes the active segment. Safe to call on a log with
+// no open segment (a no-op).
+func (l *SessionLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	syncErr := l.file.Sync()
+	closeErr := l.file.Close()
+	l.file = nil
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+// segmentFiles lists segment file names in seq order (the zero-padded,
+// first-seq-in-segment name sorts lexically the same as numerically).
+func (l *SessionLog) segmentFiles() ([]string, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".jsonl") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// readAll loads every op across every segment file, in seq order.
+func (l *SessionLog) readAll() ([]Op, error) {
+	names, err := l.segmentFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []Op
+	for _, name := range names {
+		segOps, err := readSegment(filepath.Join(l.dir, name))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, segOps...)
+	}
+	return ops, nil
+}
+
+func readSegment(path string) ([]Op, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ops []Op
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var op Op
+		if err := json.Unmarshal(line, &op); err != nil {
+			return nil, fmt.Errorf("corrupt op in %s: %w", filepath.Base(path), err)
+		}
+		ops = append(ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return ops, nil
+}
+
+// ReadSince returns every op with Seq >= since, in order.
+func (l *SessionLog) ReadSince(since uint64) ([]Op, error) {
+	ops, err := l.readAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range ops {
+		if op.Seq >= since {
+			return ops[i:], nil
+		}
+	}
+	return nil, nil
+}
+
+// CompactBefore permanently deletes every op with Seq < seq. Callers that
+// need the display state those ops produced should replay them into a
+// terminal emulator first. The currently active (still being appended to)
+// segment is left untouched even if seq reaches into it, since its ops are
+// always the most recent and so never the ones being compacted away in
+// practice.
+func (l *SessionLog) CompactBefore(seq uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names, err := l.segmentFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		path := filepath.Join(l.dir, name)
+		if l.file != nil && path == l.segPath(l.fileFirst) {
+			continue
+		}
+
+		ops, err := readSegment(path)
+		if err != nil {
+			return err
+		}
+
+		var kept []Op
+		for _, op := range ops {
+			if op.Seq >= seq {
+				kept = append(kept, op)
+			}
+		}
+
+		switch {
+		case len(kept) == len(ops):
+			// Nothing in this segment is old enough to drop.
+		case len(kept) == 0:
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		default:
+			if err := rewriteSegment(path, kept); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func rewriteSegment(path string, ops []Op) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		data, err := json.Marshal(op)
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Verify re-checks the hash chain, returning the seq of the first broken
+// link, or -1 if the whole chain is intact.
+func (l *SessionLog) Verify() (brokenAt int64, err error) {
+	ops, err := l.readAll()
+	if err != nil {
+		return -1, err
+	}
+
+	prevHash := ""
+	for _, op := range ops {
+		want := hashOp(op.Seq, op.Timestamp, op.Kind, op.Payload, prevHash)
+		if op.PrevHash != prevHash || op.Hash != want {
+			return int64(op.Seq), nil
+		}
+		prevHash = op.Hash
+	}
+	return -1, nil
+}
@@ -0,0 +1,117 @@
+package pty
+
+import "testing"
+
+func TestSessionLogAppendReadVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := OpenSessionLog(dir, "agent-1")
+	if err != nil {
+		t.Fatalf("OpenSessionLog: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := log.Append(OpStdout, []byte("frame")); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	ops, err := log.ReadSince(0)
+	if err != nil {
+		t.Fatalf("ReadSince: %v", err)
+	}
+	if len(ops) != 5 {
+		t.Fatalf("got %d ops, want 5", len(ops))
+	}
+	for i, op := range ops {
+		if op.Seq != uint64(i) {
+			t.Errorf("ops[%d].Seq = %d, want %d", i, op.Seq, i)
+		}
+	}
+
+	if brokenAt, err := log.Verify(); err != nil || brokenAt != -1 {
+		t.Fatalf("Verify() = (%d, %v), want (-1, nil)", brokenAt, err)
+	}
+}
+
+func TestSessionLogResumesSeqAndHashChain(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := OpenSessionLog(dir, "agent-1")
+	if err != nil {
+		t.Fatalf("OpenSessionLog: %v", err)
+	}
+	if _, err := log.Append(OpStdout, []byte("a")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if _, err := log.Append(OpStdout, []byte("b")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	log.Close()
+
+	reopened, err := OpenSessionLog(dir, "agent-1")
+	if err != nil {
+		t.Fatalf("reopen OpenSessionLog: %v", err)
+	}
+	if got := reopened.Seq(); got != 2 {
+		t.Fatalf("Seq() after reopen = %d, want 2", got)
+	}
+	op, err := reopened.Append(OpStdout, []byte("c"))
+	if err != nil {
+		t.Fatalf("Append after reopen: %v", err)
+	}
+	if op.Seq != 2 {
+		t.Fatalf("new op Seq = %d, want 2", op.Seq)
+	}
+	reopened.Close()
+
+	if brokenAt, err := reopened.Verify(); err != nil || brokenAt != -1 {
+		t.Fatalf("Verify() after reopen = (%d, %v), want (-1, nil)", brokenAt, err)
+	}
+}
+
+func TestSessionLogCompactBefore(t *testing.T) {
+	dir := t.TempDir()
+
+	log, err := OpenSessionLog(dir, "agent-1")
+	if err != nil {
+		t.Fatalf("OpenSessionLog: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := log.Append(OpStdout, []byte("frame")); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	// Force a rotation so CompactBefore has a fully old, closed segment to
+	// drop, separate from the still-active one it must leave alone.
+	if err := log.openSegment(log.seq); err != nil {
+		t.Fatalf("openSegment: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := log.Append(OpStdout, []byte("frame")); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	log.Close()
+
+	if err := log.CompactBefore(3); err != nil {
+		t.Fatalf("CompactBefore: %v", err)
+	}
+
+	ops, err := log.ReadSince(0)
+	if err != nil {
+		t.Fatalf("ReadSince: %v", err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("got %d ops after compaction, want 3", len(ops))
+	}
+	for _, op := range ops {
+		if op.Seq < 3 {
+			t.Errorf("found compacted op with Seq %d", op.Seq)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package sessiond implements cwt's session daemon: a long-lived process
+// that owns every agent's PTY for a repo and serves them to TUI clients
+// over a Unix socket, so scrollback and running agents survive a cwt
+// restart or crash instead of dying with the TUI process.
+package sessiond
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/wburton/cwt/internal/pty"
+)
+
+// SocketPath returns the Unix socket a session daemon listens on for
+// repoRoot, alongside the operation logs under the same .cwt directory.
+func SocketPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".cwt", "sessiond.sock")
+}
+
+// Daemon owns every agent's PTY for repoRoot via an ordinary, local
+// pty.Manager, and serves it to remote pty.Manager clients over a socket.
+type Daemon struct {
+	pm       *pty.Manager
+	repoRoot string
+}
+
+// NewDaemon creates a daemon for repoRoot. Call Serve to start accepting
+// connections.
+func NewDaemon(repoRoot string) *Daemon {
+	return &Daemon{pm: pty.NewManager(), repoRoot: repoRoot}
+}
+
+// Serve listens on socketPath and handles connections until the listener
+// is closed or it returns an error.
+func (d *Daemon) Serve(socketPath string) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0755); err != nil {
+		return fmt.Errorf("create socket dir: %w", err)
+	}
+	os.Remove(socketPath) // drop a stale socket left by a prior crashed daemon
+
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", socketPath, err)
+	}
+	defer ln.Close()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go d.handleConn(conn)
+	}
+}
+
+// handleConn serves requests from one client connection until it closes or
+// sends something we can't parse as a Request.
+func (d *Daemon) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			enc.Encode(Response{OK: false, Error: err.Error()})
+			continue
+		}
+		enc.Encode(d.handle(req))
+	}
+}
+
+func (d *Daemon) handle(req Request) Response {
+	switch req.Op {
+	case OpSpawn:
+		return d.handleSpawn(req)
+	case OpAttach:
+		return d.handleAttach(req)
+	case OpWrite:
+		return d.handleWrite(req)
+	case OpResize:
+		return d.handleResize(req)
+	case OpKill:
+		return d.handleKill(req)
+	case OpOutputSince:
+		return d.handleOutputSince(req)
+	default:
+		return Response{OK: false, Error: fmt.Sprintf("unknown op %q", req.Op)}
+	}
+}
+
+func (d *Daemon) handleSpawn(req Request) Response {
+	var (
+		session *pty.Session
+		err     error
+	)
+	if len(req.Argv) > 0 {
+		session, err = d.pm.SpawnAdapter(req.ID, req.Workdir, req.Task, d.repoRoot, req.Argv, req.Env)
+	} else {
+		session, err = d.pm.SpawnWithLog(req.ID, req.Workdir, req.Task, d.repoRoot)
+	}
+	if err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true, Seq: session.LogSeq()}
+}
+
+// handleAttach connects to an already-running session if the daemon still
+// has one for req.ID (the common case: the TUI restarted, the daemon
+// didn't), or revives it from its operation log otherwise. Either way it
+// replies with the session's current on-screen buffer so the client can
+// show exactly where the agent left off.
+func (d *Daemon) handleAttach(req Request) Response {
+	if session, ok := d.pm.Get(req.ID); ok {
+		return Response{OK: true, Data: []byte(session.Output()), Seq: session.LogSeq()}
+	}
+
+	rows, cols := req.Rows, req.Cols
+	if rows == 0 {
+		rows = 24
+	}
+	if cols == 0 {
+		cols = 80
+	}
+
+	var (
+		session *pty.Session
+		err     error
+	)
+	if len(req.Argv) > 0 {
+		session, err = d.pm.ResumeAdapter(req.ID, req.Workdir, req.Task, d.repoRoot, rows, cols, req.Argv, req.Env)
+	} else {
+		session, err = d.pm.Resume(req.ID, req.Workdir, req.Task, d.repoRoot, rows, cols)
+	}
+	if err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true, Data: []byte(session.Output()), Seq: session.LogSeq()}
+}
+
+func (d *Daemon) handleWrite(req Request) Response {
+	session, ok := d.pm.Get(req.ID)
+	if !ok {
+		return Response{OK: false, Error: fmt.Sprintf("session %s not found", req.ID)}
+	}
+	if _, err := session.Write(req.Data); err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true}
+}
+
+func (d *Daemon) handleResize(req Request) Response {
+	session, ok := d.pm.Get(req.ID)
+	if !ok {
+		return Response{OK: false, Error: fmt.Sprintf("session %s not found", req.ID)}
+	}
+	if err := session.Resize(uint16(req.Rows), uint16(req.Cols)); err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true}
+}
+
+func (d *Daemon) handleKill(req Request) Response {
+	if err := d.pm.Kill(req.ID); err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true}
+}
+
+func (d *Daemon) handleOutputSince(req Request) Response {
+	session, ok := d.pm.Get(req.ID)
+	if !ok {
+		return Response{OK: false, Error: fmt.Sprintf("session %s not found", req.ID)}
+	}
+	data, next, err := session.LogOpsSince(req.Since)
+	if err != nil {
+		return Response{OK: false, Error: err.Error()}
+	}
+	return Response{OK: true, Data: data, Seq: next}
+}
@@ -0,0 +1,35 @@
+package sessiond
+
+// Request and Response are the daemon's wire protocol: one JSON object per
+// line over a Unix socket, so a client can keep a connection open and send
+// another request without a length-prefixed framer.
+type Request struct {
+	Op      string   `json:"op"`
+	ID      string   `json:"id"`
+	Workdir string   `json:"workdir,omitempty"`
+	Task    string   `json:"task,omitempty"`
+	Argv    []string `json:"argv,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Data    []byte   `json:"data,omitempty"`
+	Rows    int      `json:"rows,omitempty"`
+	Cols    int      `json:"cols,omitempty"`
+	Since   uint64   `json:"since,omitempty"`
+}
+
+// Response is the daemon's reply to a Request.
+type Response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Data  []byte `json:"data,omitempty"`
+	Seq   uint64 `json:"seq,omitempty"`
+}
+
+// Op values for Request.Op.
+const (
+	OpSpawn       = "spawn"        // start a brand-new PTY for Request.ID
+	OpAttach      = "attach"       // connect to ID's running PTY, or revive it from its log
+	OpWrite       = "write"        // send Request.Data to ID's PTY
+	OpResize      = "resize"       // resize ID's PTY to Rows x Cols
+	OpKill        = "kill"         // terminate ID's PTY and forget it
+	OpOutputSince = "output_since" // fetch output logged for ID since Request.Since
+)
@@ -1,25 +1,41 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	agentpkg "github.com/wburton/cwt/internal/agent"
 	"github.com/wburton/cwt/internal/pty"
+	"github.com/wburton/cwt/internal/vterm"
 	"github.com/wburton/cwt/internal/worktree"
 )
 
 // Tab represents a single tab in the TUI
 type Tab struct {
-	ID        string
-	Name      string
-	IsMain    bool
-	SessionID string
-	Agent     *worktree.Agent
+	ID          string
+	Name        string
+	IsMain      bool
+	SessionID   string
+	Agent       *worktree.Agent
+	Term        *vterm.Terminal
+	AdapterName string
 }
 
+// focusState is which modal view (if any) owns key input. Future overlays
+// (help, diff, log) register a new value here instead of adding another
+// ad-hoc bool to Model.
+type focusState int
+
+const (
+	focusSession focusState = iota
+	focusInput
+	focusPalette
+)
+
 // Model is the main bubbletea model
 type Model struct {
 	tabs        []Tab
@@ -28,63 +44,118 @@ type Model struct {
 	wtManager   *worktree.Manager
 	width       int
 	height      int
-	inputMode   bool
+	focus       focusState
 	inputBuffer string
 	inputPrompt string
 	inputAction func(string)
 	quitting    bool
 	ready       bool
+	conflict    *conflictModel
+	palette     *paletteModel
+	diff        *diffModel
+	preview     string
+	labelFilter string
+	layout      layoutKind
+	focusedPane int
+	killOnQuit  bool
+	lastErr     error
 }
 
-// NewModel creates a new TUI model
-func NewModel(repoRoot string) (*Model, error) {
+// NewModel creates a new TUI model. killOnQuit controls what Alt+Q does to
+// running agents: when false (the default, `cwt` with no flag) it detaches
+// from the session daemon and leaves them running for the next cwt start
+// to pick back up; when true (`cwt --kill`) it stops them outright.
+func NewModel(repoRoot string, killOnQuit bool) (*Model, error) {
 	wtManager, err := worktree.NewManager(repoRoot)
 	if err != nil {
 		return nil, err
 	}
 
+	ptyManager, err := pty.NewRemoteManager(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session daemon: %w", err)
+	}
+
 	m := &Model{
 		tabs:       []Tab{},
 		activeTab:  0,
-		ptyManager: pty.NewManager(),
+		ptyManager: ptyManager,
 		wtManager:  wtManager,
 		width:      80,
 		height:     24,
+		killOnQuit: killOnQuit,
 	}
 
-	// Create main tab
-	_, err = m.ptyManager.Spawn("main", repoRoot, "Main orchestrator")
-	if err != nil {
-		return nil, fmt.Errorf("failed to spawn main session: %w", err)
+	// Create main tab, resuming its operation log if cwt was restarted
+	if _, err := m.ptyManager.Resume("main", repoRoot, "Main orchestrator", repoRoot, 24, 80); err != nil {
+		if _, err := m.ptyManager.SpawnWithLog("main", repoRoot, "Main orchestrator", repoRoot); err != nil {
+			return nil, fmt.Errorf("failed to spawn main session: %w", err)
+		}
 	}
 
-	m.tabs = append(m.tabs, Tab{
+	mainTab := Tab{
 		ID:        "main",
 		Name:      "Main",
 		IsMain:    true,
 		SessionID: "main",
-	})
+	}
+	if session, ok := m.ptyManager.Get("main"); ok {
+		mainTab.Term = attachTerm(session, m.height-2, m.width)
+	}
+	m.tabs = append(m.tabs, mainTab)
 
-	// Restore existing agents
+	// Restore existing agents, reattaching to their operation logs so
+	// scrollback survives a cwt restart instead of starting from blank.
 	for _, agent := range wtManager.ListAgents() {
 		if agent.Status == worktree.StatusRunning {
-			_, err := m.ptyManager.Spawn(agent.ID, agent.Worktree, agent.Task)
+			ad, ok := agentpkg.Get(agent.AdapterName)
+			if !ok {
+				ad = agentpkg.Default()
+			}
+			argv, env, err := ad.Command(agent.Worktree, agent.Task)
 			if err != nil {
-				continue // Skip failed sessions
+				continue // Skip sessions whose adapter can't build a command
 			}
 
-			m.tabs = append(m.tabs, Tab{
-				ID:        agent.ID,
-				Name:      truncate(agent.Task, 15),
-				SessionID: agent.ID,
-				Agent:     agent,
-			})
+			_, err = m.ptyManager.ResumeAdapter(agent.ID, agent.Worktree, agent.Task, repoRoot, 24, 80, argv, env)
+			if err != nil {
+				_, err = m.ptyManager.SpawnAdapter(agent.ID, agent.Worktree, agent.Task, repoRoot, argv, env)
+				if err != nil {
+					continue // Skip failed sessions
+				}
+			}
+
+			tab := Tab{
+				ID:          agent.ID,
+				Name:        truncate(agent.Task, 15),
+				SessionID:   agent.ID,
+				Agent:       agent,
+				AdapterName: ad.Name(),
+			}
+			if session, ok := m.ptyManager.Get(agent.ID); ok {
+				tab.Term = attachTerm(session, m.height-2, m.width)
+			}
+			m.tabs = append(m.tabs, tab)
 		}
 	}
 
 	return m, nil
 }
 
+// attachTerm creates a vterm.Terminal sized rows x cols for session, seeds
+// it with whatever output the session already has buffered (e.g. replayed
+// from a resumed operation log), and wires future output into it so the
+// emulator stays current without the TUI re-parsing the whole buffer
+// on every render.
+func attachTerm(session *pty.Session, rows, cols int) *vterm.Terminal {
+	term := vterm.New(rows, cols)
+	term.Write([]byte(session.Output()))
+	session.SetOutputCallback(func(data []byte) {
+		term.Write(data)
+	})
+	return term
+}
+
 // truncate shortens a string to max length
 func truncate(s string, max int) string {
 	if len(s) <= max {
@@ -119,50 +190,100 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
-		// Resize all PTY sessions (account for tab bar and status bar)
-		termHeight := m.height - 2
-		if termHeight < 1 {
-			termHeight = 1
-		}
-		m.ptyManager.ResizeAll(termHeight, m.width)
+		m.resizePanes()
 		return m, nil
 
 	case tickMsg:
 		// Just trigger redraw
 		return m, tickCmd()
+
+	case conflictResolvedMsg:
+		if m.conflict == nil || m.conflict.agentID != msg.agentID {
+			return m, nil
+		}
+
+		remaining := conflictsLeft(m.conflict.source, m.conflict.agentID, m.wtManager)
+		if len(remaining) > 0 {
+			m.conflict.files = remaining
+			if m.conflict.cursor >= len(m.conflict.files) {
+				m.conflict.cursor = 0
+			}
+			return m, nil
+		}
+
+		if m.conflict.source == "update" {
+			// The rebase/merge already ran in the agent's worktree;
+			// resolving the last file finished it.
+			m.conflict = nil
+			return m, nil
+		}
+		// All conflicts resolved and staged: conclude the merge HasConflicts
+		// left in progress. Leave the overlay up on failure so esc can
+		// still reach AbortMerge instead of stranding the in-progress merge.
+		if err := m.wtManager.FinishMerge(msg.agentID); err != nil {
+			return m, nil
+		}
+		m.conflict = nil
+		return m.closeTabByAgentID(msg.agentID)
 	}
 
 	return m, nil
 }
 
 func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle input mode first
-	if m.inputMode {
+	if m.conflict != nil {
+		return m.conflict.update(&m, msg)
+	}
+
+	if m.focus == focusPalette {
+		return m.palette.update(&m, msg)
+	}
+
+	if m.diff != nil {
+		return m.diff.update(&m, msg)
+	}
+
+	if m.focus == focusInput {
 		return m.handleInputMode(msg)
 	}
 
+	// A preview pane (e.g. a diff) is read-only; esc dismisses it.
+	if m.preview != "" {
+		if msg.String() == "esc" {
+			m.preview = ""
+		}
+		return m, nil
+	}
+
 	// Check for our control keys
 	keyStr := msg.String()
 
 	switch keyStr {
-	// Alt+Left - previous tab
+	// Ctrl+P - command palette
+	case "ctrl+p":
+		m.focus = focusPalette
+		m.palette = newPaletteModel(&m)
+		return m, nil
+	// Alt+Left - previous tab (within the active label filter, if any)
 	case "alt+left", "alt+[1;3D":
-		if m.activeTab > 0 {
-			m.activeTab--
+		visible := m.visibleTabs()
+		if idx := m.activeVisibleIndex(visible); idx > 0 {
+			m.setActiveByID(visible[idx-1].ID)
 		}
 		return m, nil
 
-	// Alt+Right - next tab
+	// Alt+Right - next tab (within the active label filter, if any)
 	case "alt+right", "alt+[1;3C":
-		if m.activeTab < len(m.tabs)-1 {
-			m.activeTab++
+		visible := m.visibleTabs()
+		if idx := m.activeVisibleIndex(visible); idx >= 0 && idx < len(visible)-1 {
+			m.setActiveByID(visible[idx+1].ID)
 		}
 		return m, nil
 
 	// Alt+N - new agent
 	case "alt+n":
-		m.inputMode = true
-		m.inputPrompt = "Task description: "
+		m.focus = focusInput
+		m.inputPrompt = "Task description (task@adapter, e.g. fix bug@aider): "
 		m.inputBuffer = ""
 		m.inputAction = m.createNewAgent
 		return m, nil
@@ -181,10 +302,53 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	// Alt+Q or Ctrl+C - quit
+	// Alt+U - update current agent from its base branch
+	case "alt+u":
+		if m.activeTab > 0 {
+			return m.updateCurrentTab()
+		}
+		return m, nil
+
+	// Alt+F - filter tabs by label selector
+	case "alt+f":
+		m.focus = focusInput
+		m.inputPrompt = "Label filter (e.g. area=api/*,risk!=high): "
+		m.inputBuffer = m.labelFilter
+		m.inputAction = m.setLabelFilter
+		return m, nil
+
+	// Alt+D - view diff/log for the current agent tab
+	case "alt+d":
+		if tab := m.currentTab(); tab != nil && tab.Agent != nil {
+			m.diff = m.newDiffOverlay(tab.Agent.ID, false)
+		}
+		return m, nil
+
+	// Alt+S - cycle split layout
+	case "alt+s":
+		m.layout = m.layout.next()
+		m.resizePanes()
+		return m, nil
+
+	// Alt+H/J/K/L - move focus between panes
+	case "alt+h":
+		return m.movePaneFocus(0, -1), nil
+	case "alt+l":
+		return m.movePaneFocus(0, 1), nil
+	case "alt+k":
+		return m.movePaneFocus(-1, 0), nil
+	case "alt+j":
+		return m.movePaneFocus(1, 0), nil
+
+	// Alt+Q or Ctrl+C - quit. Detaches from the session daemon so agents
+	// keep running in the background unless --kill was passed.
 	case "alt+q", "ctrl+c":
 		m.quitting = true
-		m.ptyManager.StopAll()
+		if m.killOnQuit {
+			m.ptyManager.StopAll()
+		} else {
+			m.ptyManager.DetachAll()
+		}
 		return m, tea.Quit
 	}
 
@@ -297,12 +461,12 @@ func (m Model) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.inputAction != nil && m.inputBuffer != "" {
 			m.inputAction(m.inputBuffer)
 		}
-		m.inputMode = false
+		m.focus = focusSession
 		m.inputBuffer = ""
 		return m, nil
 
 	case tea.KeyEscape:
-		m.inputMode = false
+		m.focus = focusSession
 		m.inputBuffer = ""
 		return m, nil
 
@@ -325,16 +489,33 @@ func (m Model) handleInputMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) createNewAgent(task string) {
-	agent, err := m.wtManager.CreateWorktree(task)
+	m.lastErr = nil
+	task, labels := parseTaskLabels(task)
+	task, adapterName := parseTaskAdapter(task)
+
+	ad, ok := agentpkg.Get(adapterName)
+	if !ok {
+		ad = agentpkg.Default()
+	}
+
+	agent, err := m.wtManager.CreateWorktree(task, labels, ad.Name())
 	if err != nil {
-		// TODO: Show error to user
+		m.lastErr = fmt.Errorf("create worktree: %w", err)
 		return
 	}
 
-	_, err = m.ptyManager.Spawn(agent.ID, agent.Worktree, agent.Task)
+	argv, env, err := ad.Command(agent.Worktree, agent.Task)
+	if err != nil {
+		m.wtManager.RemoveWorktree(agent.ID)
+		m.lastErr = fmt.Errorf("build agent command: %w", err)
+		return
+	}
+
+	_, err = m.ptyManager.SpawnAdapter(agent.ID, agent.Worktree, agent.Task, m.wtManager.GetRepoRoot(), argv, env)
 	if err != nil {
 		// Clean up worktree on failure
 		m.wtManager.RemoveWorktree(agent.ID)
+		m.lastErr = fmt.Errorf("spawn agent: %w", err)
 		return
 	}
 
@@ -343,19 +524,124 @@ func (m *Model) createNewAgent(task string) {
 	if termHeight < 1 {
 		termHeight = 1
 	}
+	tab := Tab{
+		ID:          agent.ID,
+		Name:        truncate(task, 15),
+		SessionID:   agent.ID,
+		Agent:       agent,
+		AdapterName: ad.Name(),
+	}
 	if session, ok := m.ptyManager.Get(agent.ID); ok {
-		session.Resize(termHeight, m.width)
+		session.Resize(uint16(termHeight), uint16(m.width))
+		tab.Term = attachTerm(session, termHeight, m.width)
 	}
 
-	m.tabs = append(m.tabs, Tab{
-		ID:        agent.ID,
-		Name:      truncate(task, 15),
-		SessionID: agent.ID,
-		Agent:     agent,
-	})
+	m.tabs = append(m.tabs, tab)
 	m.activeTab = len(m.tabs) - 1
 }
 
+// setLabelFilter compiles expr as a label selector and stores it as the
+// active tab filter. An invalid expression clears the filter instead of
+// leaving the user stuck looking at an empty tab bar. A valid filter is
+// also saved to State.LabelSelectors so it survives across TUI restarts.
+func (m *Model) setLabelFilter(expr string) {
+	if _, err := worktree.ParseSelector(expr); err != nil {
+		m.labelFilter = ""
+		return
+	}
+	m.labelFilter = expr
+	if err := m.wtManager.SaveLabelSelector(expr); err != nil {
+		m.lastErr = fmt.Errorf("save label filter: %w", err)
+	}
+}
+
+// visibleTabs returns the tabs that pass the active label filter. The main
+// tab always passes since it has no Agent/labels to filter on.
+func (m Model) visibleTabs() []Tab {
+	if m.labelFilter == "" {
+		return m.tabs
+	}
+
+	sel, err := worktree.ParseSelector(m.labelFilter)
+	if err != nil {
+		return m.tabs
+	}
+
+	var visible []Tab
+	for _, tab := range m.tabs {
+		if tab.IsMain || (tab.Agent != nil && sel.Matches(tab.Agent.Labels)) {
+			visible = append(visible, tab)
+		}
+	}
+	return visible
+}
+
+// activeVisibleIndex returns the position of the active tab (m.tabs[m.activeTab])
+// within visible, or -1 if the active tab has been filtered out.
+func (m Model) activeVisibleIndex(visible []Tab) int {
+	if m.activeTab < 0 || m.activeTab >= len(m.tabs) {
+		return -1
+	}
+	id := m.tabs[m.activeTab].ID
+	for i, tab := range visible {
+		if tab.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// setActiveByID points m.activeTab at the tab with the given ID in the
+// full, unfiltered tab list.
+func (m *Model) setActiveByID(id string) {
+	for i, tab := range m.tabs {
+		if tab.ID == id {
+			m.activeTab = i
+			return
+		}
+	}
+}
+
+// parseTaskLabels splits a trailing "[k=v,k2=v2]" suffix off task text into
+// a labels map, e.g. "fix auth bug [lang=go,area=api]".
+func parseTaskLabels(task string) (string, map[string]string) {
+	task = strings.TrimSpace(task)
+	open := strings.LastIndex(task, "[")
+	if open == -1 || !strings.HasSuffix(task, "]") {
+		return task, nil
+	}
+
+	labelPart := task[open+1 : len(task)-1]
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(labelPart, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return task, nil // not a valid label suffix; treat as plain task text
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return strings.TrimSpace(task[:open]), labels
+}
+
+// parseTaskAdapter splits a task@adapter shorthand into its task text and
+// adapter name. A trailing "@word" that doesn't name a registered adapter is
+// left alone and treated as part of the task text (e.g. an "@" in a commit
+// trailer).
+func parseTaskAdapter(task string) (string, string) {
+	at := strings.LastIndex(task, "@")
+	if at == -1 {
+		return task, ""
+	}
+
+	name := strings.TrimSpace(task[at+1:])
+	if _, ok := agentpkg.Get(name); !ok {
+		return task, ""
+	}
+
+	return strings.TrimSpace(task[:at]), name
+}
+
 func (m Model) closeCurrentTab() (tea.Model, tea.Cmd) {
 	if m.activeTab == 0 {
 		return m, nil // Can't close main tab
@@ -381,6 +667,7 @@ func (m Model) closeCurrentTab() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) mergeCurrentTab() (tea.Model, tea.Cmd) {
+	m.lastErr = nil
 	if m.activeTab == 0 {
 		return m, nil
 	}
@@ -390,14 +677,106 @@ func (m Model) mergeCurrentTab() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// TODO: Invoke merge orchestrator instead of direct merge
-	if err := m.wtManager.Merge(tab.Agent.ID); err != nil {
-		// TODO: Show error
+	wouldConflict, err := m.wtManager.WouldConflict(tab.Agent.ID)
+	if err != nil {
+		m.lastErr = fmt.Errorf("check merge conflicts: %w", err)
+		return m, nil
+	}
+	if !wouldConflict {
+		// No conflicts predicted: let the user review the diff/log before
+		// it merges, without touching either branch yet.
+		m.diff = m.newDiffOverlay(tab.Agent.ID, true)
+		return m, nil
+	}
+
+	conflicts, err := m.wtManager.HasConflicts(tab.Agent.ID)
+	if err != nil {
+		m.lastErr = fmt.Errorf("list merge conflicts: %w", err)
+		return m, nil
+	}
+	if len(conflicts) == 0 {
+		// The merge went through clean despite the preview (e.g. the base
+		// branch moved again in between); nothing left to resolve.
+		return m.closeTabByAgentID(tab.Agent.ID)
+	}
+	m.conflict = newConflictModel(tab.Agent.ID, "merge", conflicts)
+	return m, nil
+}
+
+// mergeSelector merges every agent matching expr without a per-agent
+// confirm step (unlike mergeCurrentTab, which always previews one agent's
+// diff first): batch merges are initiated from the palette specifically to
+// act on a whole filtered set at once. Tabs for agents that merged cleanly
+// are closed; any failures are combined into m.lastErr instead of any one
+// of them aborting the rest.
+func (m Model) mergeSelector(expr string) (tea.Model, tea.Cmd) {
+	m.lastErr = nil
+	failures := m.wtManager.MergeSelector(expr)
+
+	var errs []error
+	for id, err := range failures {
+		if id == "" {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", id, err))
+	}
+	if len(errs) > 0 {
+		m.lastErr = fmt.Errorf("merge all matching %q: %w", expr, errors.Join(errs...))
+	}
+
+	for _, tab := range m.tabs {
+		if tab.Agent == nil || tab.Agent.Status != worktree.StatusMerged {
+			continue
+		}
+		if _, failed := failures[tab.Agent.ID]; failed {
+			continue
+		}
+		next, _ := m.closeTabByAgentID(tab.Agent.ID)
+		m = next.(Model)
+	}
+	return m, nil
+}
+
+// newDiffOverlay builds the diff/log overlay for agentID, sized to the
+// session area. confirm selects the read-only viewer (Alt+D) vs. the
+// "confirm merge?" variant mergeCurrentTab opens.
+func (m Model) newDiffOverlay(agentID string, confirm bool) *diffModel {
+	diff, _ := m.wtManager.Diff(agentID)
+	log, _ := m.wtManager.Log(agentID)
+	return newDiffModel(agentID, diff, log, m.width, m.height-2, confirm)
+}
+
+// updateCurrentTab rebases the current agent tab onto its base branch,
+// dropping into the conflict-resolution overlay if that surfaces conflicts.
+func (m Model) updateCurrentTab() (tea.Model, tea.Cmd) {
+	m.lastErr = nil
+	tab := m.tabs[m.activeTab]
+	if tab.Agent == nil {
+		return m, nil
+	}
+
+	result, err := m.wtManager.UpdateFromBase(tab.Agent.ID, worktree.UpdateRebase)
+	if err != nil {
+		m.lastErr = fmt.Errorf("update from base: %w", err)
 		return m, nil
 	}
+	if len(result.Conflicts) > 0 {
+		m.conflict = newConflictModel(tab.Agent.ID, "update", result.Conflicts)
+	}
+	return m, nil
+}
 
-	// Close the tab after merge
-	return m.closeCurrentTab()
+// closeTabByAgentID closes the tab for agentID, wherever it currently sits
+// in the tab list (the active tab may have changed since the merge began).
+func (m Model) closeTabByAgentID(agentID string) (tea.Model, tea.Cmd) {
+	for i, tab := range m.tabs {
+		if tab.Agent != nil && tab.Agent.ID == agentID {
+			m.activeTab = i
+			return m.closeCurrentTab()
+		}
+	}
+	return m, nil
 }
 
 func (m Model) currentTab() *Tab {
@@ -423,6 +802,27 @@ func (m Model) View() string {
 	b.WriteString(m.renderTabBar())
 	b.WriteString("\n")
 
+	if m.conflict != nil {
+		b.WriteString(m.conflict.view(m.width, m.height-2))
+		return b.String()
+	}
+
+	if m.focus == focusPalette && m.palette != nil {
+		b.WriteString(m.palette.view(m.width, m.height-2))
+		return b.String()
+	}
+
+	if m.diff != nil {
+		b.WriteString(m.diff.view(m.width, m.height-2))
+		return b.String()
+	}
+
+	if m.preview != "" {
+		b.WriteString(m.renderPreviewPane())
+		b.WriteString(m.renderStatusBar())
+		return b.String()
+	}
+
 	// Session output (takes remaining space)
 	b.WriteString(m.renderSession())
 
@@ -446,13 +846,18 @@ func (m Model) renderTabBar() string {
 		Background(lipgloss.Color("238")).
 		Padding(0, 1)
 
-	for i, tab := range m.tabs {
+	visible := m.visibleTabs()
+	active := m.activeVisibleIndex(visible)
+	for i, tab := range visible {
 		name := tab.Name
 		if tab.IsMain {
 			name = "● " + name
 		}
+		if tab.AdapterName != "" && tab.AdapterName != "claude" {
+			name += " [" + tab.AdapterName + "]"
+		}
 
-		if i == m.activeTab {
+		if i == active {
 			tabs = append(tabs, activeStyle.Render(name))
 		} else {
 			tabs = append(tabs, inactiveStyle.Render(name))
@@ -467,37 +872,22 @@ func (m Model) renderTabBar() string {
 		Render(tabBar)
 }
 
-func (m Model) renderSession() string {
-	termHeight := m.height - 2 // Tab bar + status bar
+// renderPreviewPane renders a read-only text preview (e.g. a diff) in
+// place of the active tab's session, padded/truncated to fit like
+// renderSession does.
+func (m Model) renderPreviewPane() string {
+	termHeight := m.height - 2
 	if termHeight < 1 {
 		termHeight = 1
 	}
 
-	tab := m.currentTab()
-	if tab == nil {
-		return strings.Repeat("\n", termHeight)
-	}
-
-	session, ok := m.ptyManager.Get(tab.SessionID)
-	if !ok {
-		return strings.Repeat("\n", termHeight)
-	}
-
-	// Get the virtual terminal output
-	output := session.Output()
-
-	// Split into lines and take last termHeight lines
-	lines := strings.Split(output, "\n")
-
-	// Ensure we have exactly termHeight lines
+	lines := strings.Split(m.preview, "\n")
 	if len(lines) > termHeight {
-		lines = lines[len(lines)-termHeight:]
+		lines = lines[:termHeight]
 	}
 	for len(lines) < termHeight {
 		lines = append(lines, "")
 	}
-
-	// Truncate lines that are too long
 	for i, line := range lines {
 		if len(line) > m.width {
 			lines[i] = line[:m.width]
@@ -513,11 +903,30 @@ func (m Model) renderStatusBar() string {
 		Background(lipgloss.Color("236")).
 		Width(m.width)
 
-	if m.inputMode {
+	if m.focus == focusInput {
 		return statusStyle.Render(m.inputPrompt + m.inputBuffer + "█")
 	}
 
+	if m.preview != "" {
+		return statusStyle.Render(" esc Close preview")
+	}
+
+	if m.lastErr != nil {
+		errStyle := lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("160")).
+			Width(m.width)
+		return errStyle.Render(" ⚠ " + m.lastErr.Error())
+	}
+
 	// Show keybinds
-	help := " ⌥← Prev │ ⌥→ Next │ ⌥N New │ ⌥M Merge │ ⌥W Close │ ⌥Q Quit"
+	help := fmt.Sprintf(
+		" ⌃P Palette │ ⌥← Prev │ ⌥→ Next │ ⌥N New │ ⌥M Merge │ ⌥D Diff │ ⌥U Update │ ⌥F Filter │ ⌥W Close │ ⌥S Layout (%s) │ ⌥Q Quit",
+		m.layout,
+	)
+	if m.layout != layoutSingle {
+		help = fmt.Sprintf(" Pane %d/%d │", m.focusedPane+1, len(m.paneTabs())) + help
+	}
 	return statusStyle.Render(help)
 }
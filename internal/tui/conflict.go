@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/wburton/cwt/internal/worktree"
+)
+
+// conflictModel is the sub-model shown when merging an agent tab hits
+// conflicts. It lists the conflicted files and, for the selected one,
+// renders the ours/theirs hunks side by side so the user can pick a
+// resolution or hand the file off to claude.
+type conflictModel struct {
+	agentID string
+	files   []worktree.ConflictedFile
+	cursor  int
+	// source distinguishes what's in progress so esc/completion know
+	// whether to abort/finish a merge (repoRoot) or an update (agent's
+	// own worktree): "merge" or "update".
+	source string
+}
+
+func newConflictModel(agentID, source string, files []worktree.ConflictedFile) *conflictModel {
+	return &conflictModel{agentID: agentID, source: source, files: files}
+}
+
+// conflictsLeft reports the conflicts still unresolved for agentID. Both
+// "merge" and "update" leave a real merge/rebase in progress (in the main
+// checkout and the agent's own worktree respectively) once conflicts are
+// found, so both just reread the conflicted paths in place.
+func conflictsLeft(source, agentID string, mgr *worktree.Manager) []worktree.ConflictedFile {
+	if source == "update" {
+		remaining, _ := mgr.RemainingConflicts(agentID)
+		return remaining
+	}
+	remaining, _ := mgr.RemainingMergeConflicts(agentID)
+	return remaining
+}
+
+// conflictResolvedMsg is emitted after a file's resolution (or escalation)
+// has been applied, so Model can check whether any conflicts remain.
+type conflictResolvedMsg struct {
+	agentID string
+}
+
+func (c *conflictModel) update(m *Model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		if c.source == "update" {
+			m.wtManager.AbortWorktreeOperation(c.agentID)
+		} else {
+			m.wtManager.AbortMerge(c.agentID)
+		}
+		m.conflict = nil
+		return m, nil
+
+	case "up", "k":
+		if c.cursor > 0 {
+			c.cursor--
+		}
+		return m, nil
+
+	case "down", "j":
+		if c.cursor < len(c.files)-1 {
+			c.cursor++
+		}
+		return m, nil
+
+	case "o": // keep ours
+		return m, c.resolveCurrent(m, worktree.ResolveOurs)
+
+	case "t": // keep theirs
+		return m, c.resolveCurrent(m, worktree.ResolveTheirs)
+
+	case "b": // keep both
+		return m, c.resolveCurrent(m, worktree.ResolveBoth)
+
+	case "c": // hand off to claude in the agent's own PTY
+		return m, c.escalateCurrent(m)
+	}
+
+	return m, nil
+}
+
+func (c *conflictModel) resolveCurrent(m *Model, resolution worktree.ConflictResolution) tea.Cmd {
+	if c.cursor >= len(c.files) {
+		return nil
+	}
+	path := c.files[c.cursor].Path
+	agentID := c.agentID
+	source := c.source
+	return func() tea.Msg {
+		if source == "update" {
+			m.wtManager.ResolveConflict(agentID, path, resolution)
+		} else {
+			m.wtManager.ResolveMergeConflict(agentID, path, resolution)
+		}
+		return conflictResolvedMsg{agentID: agentID}
+	}
+}
+
+func (c *conflictModel) escalateCurrent(m *Model) tea.Cmd {
+	if c.cursor >= len(c.files) {
+		return nil
+	}
+	file := c.files[c.cursor]
+	agentID := c.agentID
+
+	if session, ok := m.ptyManager.Get(agentID); ok {
+		prompt := fmt.Sprintf(
+			"Resolve the merge conflict in %s (hunks at lines %s). "+
+				"Pick the correct resolution and stage the file with `git add`.\n",
+			file.Path, hunkRanges(file),
+		)
+		session.Write([]byte(prompt))
+	}
+
+	return func() tea.Msg {
+		m.wtManager.EscalateConflict(agentID, file.Path)
+		return conflictResolvedMsg{agentID: agentID}
+	}
+}
+
+func hunkRanges(file worktree.ConflictedFile) string {
+	var ranges []string
+	for _, h := range file.Hunks {
+		ranges = append(ranges, fmt.Sprintf("%d-%d", h.StartLine, h.EndLine))
+	}
+	return strings.Join(ranges, ", ")
+}
+
+func (c *conflictModel) view(width, height int) string {
+	var b strings.Builder
+
+	listStyle := lipgloss.NewStyle().Width(width/3).Padding(0, 1)
+	oursStyle := lipgloss.NewStyle().Width(width/3).Foreground(lipgloss.Color("76")).Padding(0, 1)
+	theirsStyle := lipgloss.NewStyle().Width(width/3).Foreground(lipgloss.Color("204")).Padding(0, 1)
+
+	var list strings.Builder
+	for i, f := range c.files {
+		marker := "  "
+		if i == c.cursor {
+			marker = "▸ "
+		}
+		fmt.Fprintf(&list, "%s%s [%s]\n", marker, f.Path, f.Kind)
+	}
+
+	var ours, theirs strings.Builder
+	if c.cursor < len(c.files) {
+		for _, h := range c.files[c.cursor].Hunks {
+			fmt.Fprintf(&ours, "@@ %d-%d @@\n%s\n\n", h.StartLine, h.EndLine, h.Ours)
+			fmt.Fprintf(&theirs, "@@ %d-%d @@\n%s\n\n", h.StartLine, h.EndLine, h.Theirs)
+		}
+	}
+
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+		listStyle.Render(list.String()),
+		oursStyle.Render("ours\n"+ours.String()),
+		theirsStyle.Render("theirs\n"+theirs.String()),
+	))
+	b.WriteString("\n")
+	b.WriteString(" o ours │ t theirs │ b both │ c hand off to claude │ esc abort merge")
+
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// diffModel is the Alt+D overlay: a scrollable, read-only view of an
+// agent's diff and commit log against its base branch. With confirm set,
+// it's the "confirm merge?" variant mergeCurrentTab opens instead of
+// merging blind — y/enter proceeds with the merge, esc cancels.
+type diffModel struct {
+	agentID string
+	vp      viewport.Model
+	confirm bool
+}
+
+var (
+	diffAddStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("76"))  // green
+	diffDelStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("204")) // red
+	diffHunkStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("80"))  // cyan
+)
+
+// newDiffModel renders diff/log into a viewport sized width x height.
+func newDiffModel(agentID string, diff, log []byte, width, height int, confirm bool) *diffModel {
+	vp := viewport.New(width, height-1)
+	vp.SetContent(renderDiffContent(diff, log))
+	return &diffModel{agentID: agentID, vp: vp, confirm: confirm}
+}
+
+// renderDiffContent styles diff hunks (green +/red -/cyan @@) under a
+// oneline commit log header.
+func renderDiffContent(diff, log []byte) string {
+	var b strings.Builder
+
+	if len(log) > 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Render("Commits"))
+		b.WriteString("\n")
+		b.WriteString(string(log))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Diff"))
+	b.WriteString("\n")
+	for _, line := range strings.Split(string(diff), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			b.WriteString(line)
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(diffAddStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(diffDelStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(diffHunkStyle.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func (d *diffModel) update(m *Model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.diff = nil
+		return m, nil
+
+	case "y", "enter":
+		if !d.confirm {
+			break
+		}
+		agentID := d.agentID
+		m.diff = nil
+		if err := m.wtManager.Merge(agentID); err != nil {
+			m.lastErr = fmt.Errorf("merge: %w", err)
+			return m, nil
+		}
+		return m.closeTabByAgentID(agentID)
+	}
+
+	var cmd tea.Cmd
+	d.vp, cmd = d.vp.Update(msg)
+	return m, cmd
+}
+
+func (d *diffModel) view(width, height int) string {
+	var b strings.Builder
+	b.WriteString(d.vp.View())
+	b.WriteString("\n")
+	if d.confirm {
+		b.WriteString(" y/enter confirm merge │ esc cancel")
+	} else {
+		b.WriteString(" ↑/↓ scroll │ esc close")
+	}
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// layoutKind is how the active tab's pane(s) tile the session area. Beyond
+// layoutSingle, several tabs render side by side so a user can watch the
+// main orchestrator and a few agent PTYs at once.
+type layoutKind int
+
+const (
+	layoutSingle layoutKind = iota
+	layoutVSplit
+	layoutHSplit
+	layoutGrid2x2
+)
+
+// next cycles through the layouts in a fixed order, for Alt+S.
+func (l layoutKind) next() layoutKind {
+	return (l + 1) % (layoutGrid2x2 + 1)
+}
+
+// grid returns the pane grid's (rows, cols) for the layout.
+func (l layoutKind) grid() (rows, cols int) {
+	switch l {
+	case layoutVSplit:
+		return 1, 2
+	case layoutHSplit:
+		return 2, 1
+	case layoutGrid2x2:
+		return 2, 2
+	default:
+		return 1, 1
+	}
+}
+
+func (l layoutKind) String() string {
+	switch l {
+	case layoutVSplit:
+		return "vsplit"
+	case layoutHSplit:
+		return "hsplit"
+	case layoutGrid2x2:
+		return "grid"
+	default:
+		return "single"
+	}
+}
+
+// paneTabs returns the tab shown in each pane (row-major), windowed over
+// the currently visible (filter-narrowed) tab list rather than the full
+// m.tabs, so a split layout only ever shows tabs the active label filter
+// admits. A pane with no corresponding tab gets the zero Tab (empty ID).
+// The focused pane always shows the active tab.
+func (m Model) paneTabs() []Tab {
+	rows, cols := m.layout.grid()
+	count := rows * cols
+
+	visible := m.visibleTabs()
+	active := m.activeVisibleIndex(visible)
+	if active < 0 {
+		active = 0
+	}
+
+	base := active - m.focusedPane
+	if base < 0 {
+		base = 0
+	}
+
+	tabs := make([]Tab, count)
+	for i := range tabs {
+		idx := base + i
+		if idx < len(visible) {
+			tabs[i] = visible[idx]
+		}
+	}
+	return tabs
+}
+
+// movePaneFocus moves focus by (dRow, dCol) in the current layout's pane
+// grid, clamping at the edges, and re-points m.activeTab at whatever tab
+// now sits behind the focused pane.
+func (m Model) movePaneFocus(dRow, dCol int) Model {
+	rows, cols := m.layout.grid()
+	row, col := m.focusedPane/cols, m.focusedPane%cols
+
+	row = clampInt(row+dRow, 0, rows-1)
+	col = clampInt(col+dCol, 0, cols-1)
+	m.focusedPane = row*cols + col
+
+	if tabs := m.paneTabs(); m.focusedPane < len(tabs) && tabs[m.focusedPane].ID != "" {
+		m.setActiveByID(tabs[m.focusedPane].ID)
+	}
+	return m
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// resizePanes resizes the PTY and terminal emulator for every tab
+// currently visible in a pane to that pane's (rows, cols); tabs off
+// screen keep a full single-pane size so they render correctly as soon as
+// a pane brings them back into view.
+func (m Model) resizePanes() {
+	termHeight := m.height - 2
+	if termHeight < 1 {
+		termHeight = 1
+	}
+
+	rows, cols := m.layout.grid()
+	paneHeight, paneWidth := termHeight/rows, m.width/cols
+	if m.layout != layoutSingle {
+		paneHeight -= 2
+		paneWidth -= 2
+	}
+	if paneHeight < 1 {
+		paneHeight = 1
+	}
+	if paneWidth < 1 {
+		paneWidth = 1
+	}
+
+	shown := make(map[string]bool)
+	for _, tab := range m.paneTabs() {
+		if tab.ID == "" {
+			continue
+		}
+		shown[tab.ID] = true
+		if session, ok := m.ptyManager.Get(tab.SessionID); ok {
+			session.Resize(uint16(paneHeight), uint16(paneWidth))
+		}
+		if tab.Term != nil {
+			tab.Term.Resize(paneHeight, paneWidth)
+		}
+	}
+
+	for _, tab := range m.tabs {
+		if shown[tab.ID] {
+			continue
+		}
+		if session, ok := m.ptyManager.Get(tab.SessionID); ok {
+			session.Resize(uint16(termHeight), uint16(m.width))
+		}
+		if tab.Term != nil {
+			tab.Term.Resize(termHeight, m.width)
+		}
+	}
+}
+
+// renderSession composites every visible pane's terminal into the session
+// area: a single tab fills it, splits join panes with
+// lipgloss.JoinHorizontal/JoinVertical.
+func (m Model) renderSession() string {
+	termHeight := m.height - 2
+	if termHeight < 1 {
+		termHeight = 1
+	}
+
+	rows, cols := m.layout.grid()
+	panes := m.paneTabs()
+	paneHeight, paneWidth := termHeight/rows, m.width/cols
+
+	var rowStrs []string
+	for r := 0; r < rows; r++ {
+		var colStrs []string
+		for c := 0; c < cols; c++ {
+			pane := r*cols + c
+			colStrs = append(colStrs, m.renderPane(panes[pane], paneWidth, paneHeight, pane == m.focusedPane))
+		}
+		rowStrs = append(rowStrs, lipgloss.JoinHorizontal(lipgloss.Top, colStrs...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rowStrs...) + "\n"
+}
+
+// renderPane renders one pane's frame. In split layouts each pane gets a
+// border (highlighted when focused) so the user can see pane boundaries
+// and which one currently receives input.
+func (m Model) renderPane(tab Tab, width, height int, focused bool) string {
+	bordered := m.layout != layoutSingle
+	contentWidth, contentHeight := width, height
+	if bordered {
+		contentWidth -= 2
+		contentHeight -= 2
+	}
+	if contentWidth < 1 {
+		contentWidth = 1
+	}
+	if contentHeight < 1 {
+		contentHeight = 1
+	}
+
+	var body string
+	if tab.Term != nil {
+		body = tab.Term.Render(contentWidth, contentHeight)
+	} else {
+		body = strings.Repeat("\n", contentHeight-1)
+	}
+
+	style := lipgloss.NewStyle().Width(contentWidth).Height(contentHeight)
+	if bordered {
+		borderColor := lipgloss.Color("238")
+		if focused {
+			borderColor = lipgloss.Color("62")
+		}
+		style = style.Border(lipgloss.RoundedBorder()).BorderForeground(borderColor)
+	}
+	return style.Render(body)
+}
@@ -0,0 +1,256 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// paletteItem is one entry in the command palette: a label to fuzzy-match
+// against, and the action to run when it's chosen.
+type paletteItem struct {
+	label string
+	run   func(m *Model) (tea.Model, tea.Cmd)
+}
+
+// paletteModel is the Ctrl+P command palette: a fuzzy-filterable list of
+// tabs and actions, built fresh each time it opens so it always reflects
+// the current tab set.
+type paletteModel struct {
+	input   textinput.Model
+	items   []paletteItem
+	matches []fuzzy.Match
+	cursor  int
+}
+
+func newPaletteModel(m *Model) *paletteModel {
+	ti := textinput.New()
+	ti.Placeholder = "switch tab or run an action…"
+	ti.Focus()
+
+	p := &paletteModel{input: ti, items: paletteItems(m)}
+	p.refilter()
+	return p
+}
+
+// paletteItems builds the full, unfiltered item list: one entry per tab to
+// switch to it, plus merge/close/diff/shell actions for each agent tab.
+func paletteItems(m *Model) []paletteItem {
+	var items []paletteItem
+
+	for i, tab := range m.tabs {
+		idx := i
+		label := fmt.Sprintf("tab: %s", tab.Name)
+		if tab.Agent != nil {
+			label = fmt.Sprintf("tab: %s  [%s]  %s  %s", tab.Name, tab.Agent.Status, tab.Agent.Branch, tab.Agent.Worktree)
+		}
+		items = append(items, paletteItem{
+			label: label,
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.activeTab = idx
+				m.focus = focusSession
+				m.palette = nil
+				return m, nil
+			},
+		})
+	}
+
+	items = append(items, paletteItem{
+		label: "new agent",
+		run: func(m *Model) (tea.Model, tea.Cmd) {
+			m.palette = nil
+			m.focus = focusInput
+			m.inputPrompt = "Task description (task@adapter, e.g. fix bug@aider): "
+			m.inputBuffer = ""
+			m.inputAction = m.createNewAgent
+			return m, nil
+		},
+	})
+
+	if m.labelFilter != "" {
+		expr := m.labelFilter
+		items = append(items, paletteItem{
+			label: fmt.Sprintf("merge all matching filter %q", expr),
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.palette = nil
+				return m.mergeSelector(expr)
+			},
+		})
+	}
+
+	for _, tab := range m.tabs {
+		if tab.Agent == nil {
+			continue
+		}
+		agentID := tab.Agent.ID
+		name := tab.Name
+
+		items = append(items, paletteItem{
+			label: fmt.Sprintf("merge tab %s", name),
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.palette = nil
+				m.focus = focusSession
+				if i := m.tabIndexByAgentID(agentID); i >= 0 {
+					m.activeTab = i
+				}
+				return m.mergeCurrentTab()
+			},
+		})
+		items = append(items, paletteItem{
+			label: fmt.Sprintf("close tab %s", name),
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.palette = nil
+				m.focus = focusSession
+				if i := m.tabIndexByAgentID(agentID); i >= 0 {
+					m.activeTab = i
+				}
+				return m.closeCurrentTab()
+			},
+		})
+		items = append(items, paletteItem{
+			label: fmt.Sprintf("diff tab %s", name),
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.palette = nil
+				m.focus = focusSession
+				m.preview = m.renderDiffPreview(agentID)
+				return m, nil
+			},
+		})
+		items = append(items, paletteItem{
+			label: fmt.Sprintf("open shell in worktree %s", name),
+			run: func(m *Model) (tea.Model, tea.Cmd) {
+				m.palette = nil
+				m.focus = focusSession
+				return m.openShell(agentID)
+			},
+		})
+	}
+
+	return items
+}
+
+func (p *paletteModel) refilter() {
+	labels := make([]string, len(p.items))
+	for i, it := range p.items {
+		labels[i] = it.label
+	}
+
+	query := p.input.Value()
+	if query == "" {
+		p.matches = nil
+		for i, label := range labels {
+			p.matches = append(p.matches, fuzzy.Match{Str: label, Index: i})
+		}
+	} else {
+		p.matches = fuzzy.Find(query, labels)
+	}
+
+	if p.cursor >= len(p.matches) {
+		p.cursor = 0
+	}
+}
+
+func (p *paletteModel) update(m *Model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+p":
+		m.palette = nil
+		m.focus = focusSession
+		return m, nil
+
+	case "enter":
+		if p.cursor >= len(p.matches) {
+			return m, nil
+		}
+		item := p.items[p.matches[p.cursor].Index]
+		return item.run(m)
+
+	case "up", "ctrl+k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return m, nil
+
+	case "down", "ctrl+j":
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.refilter()
+	return m, cmd
+}
+
+func (p *paletteModel) view(width, height int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Width(width).Render(p.input.View()))
+	b.WriteString("\n\n")
+
+	cursorStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15")).Background(lipgloss.Color("62"))
+	listHeight := height - 3
+	for i, match := range p.matches {
+		if i >= listHeight {
+			break
+		}
+		if i == p.cursor {
+			b.WriteString(cursorStyle.Render("▸ " + match.Str))
+		} else {
+			b.WriteString("  " + match.Str)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// tabIndexByAgentID finds the tab index for agentID, since a palette item
+// built when the palette opened may be stale by the time it's chosen.
+func (m *Model) tabIndexByAgentID(agentID string) int {
+	for i, tab := range m.tabs {
+		if tab.Agent != nil && tab.Agent.ID == agentID {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderDiffPreview renders agentID's diff against its base branch as
+// plain text for the read-only preview pane.
+func (m *Model) renderDiffPreview(agentID string) string {
+	diff, err := m.wtManager.GetDiff(agentID)
+	if err != nil {
+		return fmt.Sprintf("diff error: %v", err)
+	}
+	if diff == "" {
+		return "(no changes)"
+	}
+	return diff
+}
+
+// openShell suspends the TUI and execs an interactive shell in agentID's
+// worktree, resuming the TUI when the shell exits.
+func (m *Model) openShell(agentID string) (tea.Model, tea.Cmd) {
+	agent, ok := m.wtManager.GetAgent(agentID)
+	if !ok {
+		return m, nil
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell)
+	cmd.Dir = agent.Worktree
+
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return nil
+	})
+}
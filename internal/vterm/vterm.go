@@ -0,0 +1,135 @@
+// Package vterm renders a live terminal screen with lipgloss so a TUI can
+// show whatever full-screen program (vim, htop, claude's own TUI) is
+// running inside a tab instead of mangling it by slicing raw bytes into
+// lines. The VT100/CSI parsing is vt10x's, the same emulator
+// internal/pty/replay.go already uses for headless scrollback replay; this
+// package wraps its State to add the styled-frame rendering cwt's TUI
+// needs.
+package vterm
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hinshun/vt10x"
+)
+
+// Terminal is a live, per-session virtual terminal: feed it raw PTY bytes
+// via Write, and ask it for a styled frame via Render.
+type Terminal struct {
+	vt vt10x.Terminal
+}
+
+// New creates a Terminal sized rows x cols.
+func New(rows, cols int) *Terminal {
+	return &Terminal{vt: vt10x.New(vt10x.WithSize(cols, rows))}
+}
+
+// Write feeds raw PTY output into the emulator, advancing vt10x's cursor
+// and grid state as it parses printable runes and CSI/OSC/SGR sequences.
+func (t *Terminal) Write(p []byte) (int, error) {
+	return t.vt.Write(p)
+}
+
+// Resize changes the grid size, preserving whatever content still fits in
+// the top-left corner.
+func (t *Terminal) Resize(rows, cols int) {
+	t.vt.Resize(cols, rows)
+}
+
+// Render returns a lipgloss-styled frame sized width x height, cropped or
+// padded from the emulator's current screen.
+func (t *Terminal) Render(width, height int) string {
+	t.vt.Lock()
+	defer t.vt.Unlock()
+
+	cols, rows := t.vt.Size()
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		if y > 0 {
+			b.WriteString("\n")
+		}
+		if y < rows {
+			b.WriteString(t.renderLine(y, width, cols))
+		}
+	}
+	return b.String()
+}
+
+func (t *Terminal) renderLine(y, width, cols int) string {
+	var b strings.Builder
+	var run strings.Builder
+	runStyle := lipgloss.NewStyle()
+	flush := func() {
+		if run.Len() > 0 {
+			b.WriteString(runStyle.Render(run.String()))
+			run.Reset()
+		}
+	}
+
+	for x := 0; x < width; x++ {
+		var glyph vt10x.Glyph
+		if x < cols {
+			glyph = t.vt.Cell(x, y)
+		}
+		ch := glyph.Char
+		if ch == 0 {
+			ch = ' '
+		}
+		style := glyphStyle(glyph)
+		if run.Len() > 0 && style.String() != runStyle.String() {
+			flush()
+		}
+		runStyle = style
+		run.WriteRune(ch)
+	}
+	flush()
+	return b.String()
+}
+
+// Glyph.Mode bits below mirror vt10x's own (unexported) attrReverse/
+// attrUnderline/attrBold layout for the vt10x version this module is
+// pinned to; vt10x doesn't expose them itself, only the Mode int16.
+const (
+	attrReverse = 1 << iota
+	attrUnderline
+	attrBold
+)
+
+func glyphStyle(g vt10x.Glyph) lipgloss.Style {
+	s := lipgloss.NewStyle()
+	fg, bg := colorName(g.FG), colorName(g.BG)
+	if g.Mode&attrReverse != 0 {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = "0"
+		}
+		if bg == "" {
+			bg = "7"
+		}
+	}
+	if fg != "" {
+		s = s.Foreground(lipgloss.Color(fg))
+	}
+	if bg != "" {
+		s = s.Background(lipgloss.Color(bg))
+	}
+	if g.Mode&attrBold != 0 {
+		s = s.Bold(true)
+	}
+	if g.Mode&attrUnderline != 0 {
+		s = s.Underline(true)
+	}
+	return s
+}
+
+// colorName renders an ANSI color index as the string lipgloss.Color
+// expects, or "" for vt10x's default-color sentinels (DefaultFG/DefaultBG)
+// and 256-color/true-color values this package doesn't map yet.
+func colorName(c vt10x.Color) string {
+	if !c.ANSI() {
+		return ""
+	}
+	return strconv.Itoa(int(c))
+}
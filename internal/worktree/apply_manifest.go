@@ -0,0 +1,171 @@
+package worktree
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/wburton/cwt/internal/manifest"
+)
+
+// ManifestSpawner starts an agent's PTY session with task's prompt piped
+// in. It must arrange to call onReady exactly once the task's agent
+// finishes (process exit, or manifest.SentinelLine seen in its output),
+// so ApplyManifest can spawn any tasks that depend on it.
+type ManifestSpawner func(agent *Agent, task manifest.Task, onReady func()) error
+
+// ApplyManifest builds the task DAG described by mf and, in dependency
+// order, creates a worktree for each task and hands it to spawn. A task
+// whose Base names another task branches off that task's worktree HEAD
+// (so it sees the parent's in-progress work) rather than the repo's
+// checked-out HEAD. Tasks with DependsOn aren't created until every
+// dependency has reported ready through its ManifestSpawner callback.
+func (m *Manager) ApplyManifest(mf *manifest.Manifest, spawn ManifestSpawner) ([]*Agent, error) {
+	order, err := mf.TopoSort()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	tasksByName := make(map[string]manifest.Task, len(order))
+	for _, t := range order {
+		tasksByName[t.Name] = t
+	}
+
+	var (
+		mu           sync.Mutex
+		created      []*Agent
+		agentsByTask = make(map[string]*Agent, len(order))
+		ready        = make(map[string]bool, len(order))
+		claimed      = make(map[string]bool, len(order))
+		firstErr     error
+	)
+
+	var maybeSpawn func(name string)
+	maybeSpawn = func(name string) {
+		task := tasksByName[name]
+
+		mu.Lock()
+		if claimed[name] || firstErr != nil {
+			mu.Unlock()
+			return
+		}
+		for _, dep := range task.DependsOn {
+			if !ready[dep] {
+				mu.Unlock()
+				return // a dependency hasn't finished yet
+			}
+		}
+		var baseAgent *Agent
+		if _, isTask := tasksByName[task.Base]; isTask && task.Base != task.Name {
+			parent, created := agentsByTask[task.Base]
+			if !created {
+				mu.Unlock()
+				return // base task hasn't been created yet
+			}
+			baseAgent = parent
+		}
+		// Claim the slot before releasing the lock: two onReady callbacks
+		// firing close together for a task with 2+ dependencies would
+		// otherwise both pass the checks above and double-spawn it, since
+		// agentsByTask[name] isn't set until after the slow
+		// createWorktreeFrom/spawn calls below. baseAgent is snapshotted
+		// under the same lock so resolveManifestBase never touches
+		// agentsByTask itself, which maybeSpawn mutates concurrently from
+		// other goroutines once onReady can fire from multiple PTY
+		// sessions at once.
+		claimed[name] = true
+		mu.Unlock()
+
+		baseBranch, baseCommit, err := m.resolveManifestBase(task, baseAgent)
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("task %q: %w", task.Name, err)
+			}
+			mu.Unlock()
+			return
+		}
+
+		agent, err := m.createWorktreeFrom(task.Name, baseBranch, baseCommit, task.Labels, "")
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("task %q: %w", task.Name, err)
+			}
+			mu.Unlock()
+			return
+		}
+
+		mu.Lock()
+		agentsByTask[task.Name] = agent
+		created = append(created, agent)
+		mu.Unlock()
+
+		// A task can chain off this one's worktree HEAD via Base without
+		// waiting for it to finish (DependsOn is what waits for ready);
+		// now that it's created, anything gated on that is unblocked.
+		for _, candidate := range order {
+			if candidate.Base == task.Name {
+				maybeSpawn(candidate.Name)
+			}
+		}
+
+		err = spawn(agent, task, func() {
+			mu.Lock()
+			ready[task.Name] = true
+			mu.Unlock()
+			for _, candidate := range order {
+				for _, dep := range candidate.DependsOn {
+					if dep == task.Name {
+						maybeSpawn(candidate.Name)
+					}
+				}
+			}
+		})
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("task %q: %w", task.Name, err)
+			}
+			mu.Unlock()
+		}
+	}
+
+	for _, t := range order {
+		if len(t.DependsOn) == 0 {
+			maybeSpawn(t.Name)
+		}
+	}
+
+	return created, firstErr
+}
+
+// resolveManifestBase resolves the branch/commit a task should be created
+// from: the repo's checked-out HEAD by default, baseAgent's worktree HEAD
+// if the caller found Base naming an already-created task, or a plain
+// branch name otherwise. The caller looks baseAgent up itself (under
+// whatever lock guards its agentsByTask map) rather than this function
+// doing it, since maybeSpawn calls this after releasing that lock.
+func (m *Manager) resolveManifestBase(task manifest.Task, baseAgent *Agent) (branch, commit string, err error) {
+	if task.Base == "" {
+		branch, err = m.GetCurrentBranch()
+		if err != nil {
+			return "", "", err
+		}
+		commit, err = m.GetCurrentCommit()
+		return branch, commit, err
+	}
+
+	if baseAgent != nil {
+		head, err := runGitIn(baseAgent.Worktree, "rev-parse", "HEAD")
+		if err != nil {
+			return "", "", fmt.Errorf("read HEAD of parent task %q: %w", task.Base, err)
+		}
+		return baseAgent.Branch, head, nil
+	}
+
+	commit, err = runGitIn(m.repoRoot, "rev-parse", task.Base)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve base branch %q: %w", task.Base, err)
+	}
+	return task.Base, commit, nil
+}
@@ -0,0 +1,96 @@
+package worktree
+
+import (
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/wburton/cwt/internal/manifest"
+)
+
+// newTestRepo creates a minimal git repo with one commit on "main", so
+// Manager has something real to create worktrees from.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	if err := exec.Command("sh", "-c", "echo hi > "+filepath.Join(dir, "f.txt")).Run(); err != nil {
+		t.Fatalf("seed file: %v", err)
+	}
+	run("add", "-A")
+	run("commit", "-q", "-m", "init")
+	return dir
+}
+
+// TestApplyManifestWaitsForBaseTask exercises a manifest where a task's
+// Base names another task that itself has an unmet DependsOn, so it isn't
+// created as part of the initial no-DependsOn fan-out. maybeSpawn must
+// hold off creating the Base-chained task until its parent actually
+// exists, instead of falling through to resolveManifestBase's
+// rev-parse-a-task-name-as-a-branch fallback.
+func TestApplyManifestWaitsForBaseTask(t *testing.T) {
+	repoRoot := newTestRepo(t)
+	m, err := NewManager(repoRoot)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	mf := &manifest.Manifest{Tasks: []manifest.Task{
+		{Name: "a"},
+		{Name: "b", DependsOn: []string{"a"}},
+		{Name: "c", Base: "b"},
+	}}
+
+	var mu sync.Mutex
+	var spawnOrder []string
+	spawn := func(agent *Agent, task manifest.Task, onReady func()) error {
+		mu.Lock()
+		spawnOrder = append(spawnOrder, task.Name)
+		mu.Unlock()
+		onReady()
+		return nil
+	}
+
+	created, err := m.ApplyManifest(mf, spawn)
+	if err != nil {
+		t.Fatalf("ApplyManifest: %v", err)
+	}
+	if len(created) != 3 {
+		t.Fatalf("got %d agents, want 3 (spawn order: %v)", len(created), spawnOrder)
+	}
+
+	byTask := make(map[string]*Agent, len(created))
+	for _, a := range created {
+		byTask[a.Task] = a
+	}
+	bAgent, ok := byTask["b"]
+	if !ok {
+		t.Fatalf("task b was not created")
+	}
+	cAgent, ok := byTask["c"]
+	if !ok {
+		t.Fatalf("task c was not created")
+	}
+
+	if cAgent.BaseBranch != bAgent.Branch {
+		t.Errorf("c.BaseBranch = %q, want b's branch %q (c should chain off b's worktree, not a literal ref named %q)",
+			cAgent.BaseBranch, bAgent.Branch, "b")
+	}
+	if cAgent.BaseCommit != bAgent.BaseCommit {
+		t.Errorf("c.BaseCommit = %q, want b's HEAD %q", cAgent.BaseCommit, bAgent.BaseCommit)
+	}
+
+	if spawnOrder[0] != "a" {
+		t.Errorf("spawn order = %v, want \"a\" spawned first", spawnOrder)
+	}
+}
@@ -0,0 +1,376 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictKind classifies the nature of a conflict on a single path.
+type ConflictKind string
+
+const (
+	ConflictContent      ConflictKind = "content"
+	ConflictAddAdd       ConflictKind = "add/add"
+	ConflictDeleteModify ConflictKind = "delete/modify"
+)
+
+// ConflictHunk is one conflicting region within a file, as delimited by
+// git's <<<<<<</=======/>>>>>>> markers.
+type ConflictHunk struct {
+	StartLine int    `json:"startLine"`
+	EndLine   int    `json:"endLine"`
+	Ours      string `json:"ours"`
+	Theirs    string `json:"theirs"`
+}
+
+// ConflictedFile describes one file with merge conflicts: the blobs git
+// staged for it (stage 1/2/3 of the index) and the conflicting hunks
+// within its working-tree copy.
+type ConflictedFile struct {
+	Path      string         `json:"path"`
+	Kind      ConflictKind   `json:"kind"`
+	BaseSHA   string         `json:"baseSha"`
+	OursSHA   string         `json:"oursSha"`
+	TheirsSHA string         `json:"theirsSha"`
+	Hunks     []ConflictHunk `json:"hunks"`
+}
+
+// ConflictResolution is the outcome the user (or an escalated agent) picked
+// for a conflicted file.
+type ConflictResolution string
+
+const (
+	ResolveOurs   ConflictResolution = "ours"
+	ResolveTheirs ConflictResolution = "theirs"
+	ResolveBoth   ConflictResolution = "both"
+)
+
+// WouldConflict reports, without touching either branch's real state,
+// whether merging an agent's branch into its base branch would conflict.
+// Callers use this to decide whether Merge can go straight through (e.g.
+// behind a "confirm merge?" overlay) or whether HasConflicts needs to run
+// instead.
+func (m *Manager) WouldConflict(id string) (bool, error) {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return false, fmt.Errorf("agent %s not found", id)
+	}
+
+	base, err := m.vcs.MergeBase(agent.BaseBranch, agent.Branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to find merge base: %w", err)
+	}
+
+	tree, err := m.vcs.MergeTree(base, agent.BaseBranch, agent.Branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to preview merge: %w", err)
+	}
+	return strings.Contains(tree, "<<<<<<<"), nil
+}
+
+// HasConflicts attempts the real merge of an agent's branch into its base
+// branch in the main checkout. A clean merge finishes immediately, the
+// same as Merge. A conflicting one is left in progress (mirroring how
+// UpdateFromBase leaves a conflicted merge/rebase in the agent's own
+// worktree) for RemainingMergeConflicts/ResolveMergeConflict/AbortMerge to
+// resolve there, and it returns one ConflictedFile per conflicting path.
+// Call WouldConflict first to avoid starting a merge that doesn't need
+// conflict resolution at all.
+func (m *Manager) HasConflicts(id string) ([]ConflictedFile, error) {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+
+	agent.Status = StatusMerging
+	m.state.Save()
+
+	if err := m.vcs.Checkout(agent.BaseBranch); err != nil {
+		agent.Status = StatusRunning
+		m.state.Save()
+		return nil, fmt.Errorf("failed to checkout base branch: %w", err)
+	}
+
+	msg := fmt.Sprintf("Merge %s: %s", agent.ID, agent.Task)
+	mergeErr := m.vcs.Merge(agent.Branch, msg)
+	if mergeErr == nil {
+		return nil, m.finishMerge(agent)
+	}
+
+	files, err := m.collectConflicts(m.repoRoot)
+	if err == nil && len(files) == 0 {
+		// The merge failed for a reason other than a content conflict
+		// (e.g. the checkout had local changes in the way); there's
+		// nothing for ResolveMergeConflict to fix, so clean up whatever
+		// the merge attempt left behind and surface the original
+		// failure instead of reporting "no conflicts".
+		runGitIn(m.repoRoot, "merge", "--abort")
+		agent.Status = StatusRunning
+		m.state.Save()
+		return nil, fmt.Errorf("merge failed: %w", mergeErr)
+	}
+	return files, err
+}
+
+// RemainingConflicts reports the conflicted files still unresolved in an
+// agent's worktree during an in-progress merge or rebase (started by
+// UpdateFromBase), without starting or ending the operation itself.
+func (m *Manager) RemainingConflicts(id string) ([]ConflictedFile, error) {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+	return m.collectConflicts(agent.Worktree)
+}
+
+// RemainingMergeConflicts is RemainingConflicts for a merge conflict
+// (HasConflicts), which runs in the main checkout instead of the agent's
+// own worktree.
+func (m *Manager) RemainingMergeConflicts(id string) ([]ConflictedFile, error) {
+	if _, ok := m.state.GetAgent(id); !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+	return m.collectConflicts(m.repoRoot)
+}
+
+// FinishMerge concludes a merge that HasConflicts left in progress after
+// every conflicted file has been staged via ResolveMergeConflict, the same
+// way `git commit` would conclude it by hand.
+func (m *Manager) FinishMerge(id string) error {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if _, err := runGitIn(m.repoRoot, "commit", "--no-edit"); err != nil {
+		return fmt.Errorf("failed to conclude merge: %w", err)
+	}
+	return m.finishMerge(agent)
+}
+
+// collectConflicts reads the currently-unmerged paths in worktree and
+// builds a ConflictedFile for each, without itself starting or ending any
+// merge/rebase — the caller is responsible for that.
+func (m *Manager) collectConflicts(worktree string) ([]ConflictedFile, error) {
+	unmerged, err := runGitIn(worktree, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicted files: %w", err)
+	}
+
+	var files []ConflictedFile
+	for _, path := range strings.Split(unmerged, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		cf, err := m.buildConflictedFile(worktree, path)
+		if err != nil {
+			continue
+		}
+		files = append(files, cf)
+	}
+	return files, nil
+}
+
+// buildConflictedFile reads the index stages and working-tree markers for
+// one conflicted path.
+func (m *Manager) buildConflictedFile(worktree, path string) (ConflictedFile, error) {
+	out, err := runGitIn(worktree, "ls-files", "-u", "--", path)
+	if err != nil {
+		return ConflictedFile{}, err
+	}
+
+	cf := ConflictedFile{Path: path, Kind: ConflictContent}
+	haveStage := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		sha, stage := fields[1], fields[2]
+		switch stage {
+		case "1":
+			cf.BaseSHA = sha
+		case "2":
+			cf.OursSHA = sha
+		case "3":
+			cf.TheirsSHA = sha
+		}
+		haveStage[stage] = true
+	}
+
+	switch {
+	case !haveStage["1"] && haveStage["2"] && haveStage["3"]:
+		cf.Kind = ConflictAddAdd
+	case haveStage["1"] && (!haveStage["2"] || !haveStage["3"]):
+		cf.Kind = ConflictDeleteModify
+	}
+
+	content, err := os.ReadFile(filepath.Join(worktree, path))
+	if err != nil {
+		return cf, err
+	}
+	cf.Hunks = parseConflictHunks(string(content))
+	return cf, nil
+}
+
+// parseConflictHunks scans working-tree content for conflict marker
+// regions and returns the ours/theirs text of each one.
+func parseConflictHunks(content string) []ConflictHunk {
+	lines := strings.Split(content, "\n")
+	var hunks []ConflictHunk
+	var ours, theirs []string
+	inOurs, inTheirs := false, false
+	start := 0
+
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			start = i + 1
+			inOurs, inTheirs = true, false
+			ours, theirs = nil, nil
+		case strings.HasPrefix(line, "======="):
+			if inOurs {
+				inOurs, inTheirs = false, true
+			}
+		case strings.HasPrefix(line, ">>>>>>>"):
+			if inTheirs {
+				hunks = append(hunks, ConflictHunk{
+					StartLine: start,
+					EndLine:   i + 1,
+					Ours:      strings.Join(ours, "\n"),
+					Theirs:    strings.Join(theirs, "\n"),
+				})
+			}
+			inOurs, inTheirs = false, false
+		case inOurs:
+			ours = append(ours, line)
+		case inTheirs:
+			theirs = append(theirs, line)
+		}
+	}
+	return hunks
+}
+
+// ResolveConflict applies resolution to path in the agent's worktree,
+// writing the resolved blob and staging it in the index. Used for an
+// "update" conflict (UpdateFromBase), which runs entirely inside the
+// agent's own worktree.
+func (m *Manager) ResolveConflict(id, path string, resolution ConflictResolution) error {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if err := resolveConflictAt(agent.Worktree, path, resolution); err != nil {
+		return err
+	}
+	m.recordConflictResolved(id)
+	return nil
+}
+
+// ResolveMergeConflict is ResolveConflict for a merge conflict
+// (HasConflicts), which runs in the main checkout instead of the agent's
+// own worktree.
+func (m *Manager) ResolveMergeConflict(id, path string, resolution ConflictResolution) error {
+	if _, ok := m.state.GetAgent(id); !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if err := resolveConflictAt(m.repoRoot, path, resolution); err != nil {
+		return err
+	}
+	m.recordConflictResolved(id)
+	return nil
+}
+
+// resolveConflictAt strips conflict markers from path (rooted at dir) per
+// resolution, writes the resolved blob back, and stages it.
+func resolveConflictAt(dir, path string, resolution ConflictResolution) error {
+	full := filepath.Join(dir, path)
+	content, err := os.ReadFile(full)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	resolved := applyResolution(string(content), resolution)
+	if err := os.WriteFile(full, []byte(resolved), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if _, err := runGitIn(dir, "add", "--", path); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	return nil
+}
+
+// EscalateConflict records that path was handed off (e.g. to a claude
+// session) instead of being resolved by the user directly.
+func (m *Manager) EscalateConflict(id, path string) {
+	m.recordConflictEscalated(id)
+}
+
+// AbortMerge aborts an in-progress merge on the main checkout, as left
+// behind by a Manager.Merge call that hit conflicts.
+func (m *Manager) AbortMerge(id string) error {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	delete(m.pendingConflicts, id)
+	_, err := runGitIn(m.repoRoot, "merge", "--abort")
+	agent.Status = StatusRunning
+	m.state.Save()
+	return err
+}
+
+// AbortWorktreeOperation aborts an in-progress merge or rebase within an
+// agent's own worktree, as left behind by UpdateFromBase hitting conflicts.
+func (m *Manager) AbortWorktreeOperation(id string) error {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return fmt.Errorf("agent %s not found", id)
+	}
+	if _, err := runGitIn(agent.Worktree, "merge", "--abort"); err == nil {
+		return nil
+	}
+	_, err := runGitIn(agent.Worktree, "rebase", "--abort")
+	return err
+}
+
+// applyResolution strips conflict markers from content, keeping ours,
+// theirs, or both sides per the chosen resolution.
+func applyResolution(content string, resolution ConflictResolution) string {
+	lines := strings.Split(content, "\n")
+	var out []string
+	inOurs, inTheirs := false, false
+
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			inOurs = true
+			continue
+		case strings.HasPrefix(line, "======="):
+			if inOurs {
+				inOurs, inTheirs = false, true
+				continue
+			}
+		case strings.HasPrefix(line, ">>>>>>>"):
+			inTheirs = false
+			continue
+		}
+
+		switch {
+		case inOurs:
+			if resolution == ResolveOurs || resolution == ResolveBoth {
+				out = append(out, line)
+			}
+		case inTheirs:
+			if resolution == ResolveTheirs || resolution == ResolveBoth {
+				out = append(out, line)
+			}
+		default:
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
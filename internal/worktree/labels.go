@@ -0,0 +1,191 @@
+package worktree
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+)
+
+// selectorOp is the comparison an individual selector clause performs.
+type selectorOp string
+
+const (
+	opEquals    selectorOp = "="
+	opNotEquals selectorOp = "!="
+	opIn        selectorOp = "in"
+	opNotIn     selectorOp = "notin"
+)
+
+// selectorClause is one "key[op]value" term of a label selector.
+type selectorClause struct {
+	key    string
+	op     selectorOp
+	values []string
+}
+
+// LabelSelector is a compiled, AND-of-clauses label-matching expression,
+// e.g. "area=api/*,risk!=high,lang in (go,rust)". Value patterns are
+// matched with path.Match, so clauses can glob ("area=api/*").
+type LabelSelector struct {
+	expr    string
+	clauses []selectorClause
+}
+
+// selectorCache holds compiled selectors keyed by their source expression,
+// so repeatedly filtering by the same string (e.g. from the TUI's filter
+// box on every keystroke) doesn't re-parse it each time.
+var selectorCache sync.Map // string -> *LabelSelector
+
+// ParseSelector compiles expr into a LabelSelector, returning a cached
+// instance if expr has been compiled before.
+func ParseSelector(expr string) (*LabelSelector, error) {
+	if cached, ok := selectorCache.Load(expr); ok {
+		return cached.(*LabelSelector), nil
+	}
+
+	sel := &LabelSelector{expr: expr}
+	for _, raw := range splitClauses(expr) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		clause, err := parseClause(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", expr, err)
+		}
+		sel.clauses = append(sel.clauses, clause)
+	}
+
+	selectorCache.Store(expr, sel)
+	return sel, nil
+}
+
+// splitClauses splits expr on top-level commas, i.e. commas outside any
+// "(...)" grouping, so a multi-value "in (go,rust)"/"notin (...)" clause
+// isn't torn apart at its own internal commas before parseClause sees it.
+func splitClauses(expr string) []string {
+	var clauses []string
+	depth := 0
+	start := 0
+	for i, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	clauses = append(clauses, expr[start:])
+	return clauses
+}
+
+func parseClause(raw string) (selectorClause, error) {
+	if idx := strings.Index(raw, " notin "); idx >= 0 {
+		return newSetClause(raw[:idx], opNotIn, raw[idx+len(" notin "):])
+	}
+	if idx := strings.Index(raw, " in "); idx >= 0 {
+		return newSetClause(raw[:idx], opIn, raw[idx+len(" in "):])
+	}
+
+	switch {
+	case strings.Contains(raw, "!="):
+		parts := strings.SplitN(raw, "!=", 2)
+		return selectorClause{key: strings.TrimSpace(parts[0]), op: opNotEquals, values: []string{strings.TrimSpace(parts[1])}}, nil
+	case strings.Contains(raw, "=="):
+		parts := strings.SplitN(raw, "==", 2)
+		return selectorClause{key: strings.TrimSpace(parts[0]), op: opEquals, values: []string{strings.TrimSpace(parts[1])}}, nil
+	case strings.Contains(raw, "="):
+		parts := strings.SplitN(raw, "=", 2)
+		return selectorClause{key: strings.TrimSpace(parts[0]), op: opEquals, values: []string{strings.TrimSpace(parts[1])}}, nil
+	}
+
+	return selectorClause{}, fmt.Errorf("unrecognized clause %q", raw)
+}
+
+func newSetClause(key string, op selectorOp, valueList string) (selectorClause, error) {
+	valueList = strings.TrimSpace(valueList)
+	valueList = strings.TrimPrefix(valueList, "(")
+	valueList = strings.TrimSuffix(valueList, ")")
+
+	var values []string
+	for _, v := range strings.Split(valueList, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return selectorClause{}, fmt.Errorf("%s clause for %q has no values", op, key)
+	}
+	return selectorClause{key: strings.TrimSpace(key), op: op, values: values}, nil
+}
+
+// Matches reports whether labels satisfies every clause in the selector.
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	for _, c := range s.clauses {
+		if !c.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c selectorClause) matches(labels map[string]string) bool {
+	value, ok := labels[c.key]
+
+	switch c.op {
+	case opEquals:
+		return ok && globMatch(c.values[0], value)
+	case opNotEquals:
+		return !ok || !globMatch(c.values[0], value)
+	case opIn:
+		if !ok {
+			return false
+		}
+		for _, v := range c.values {
+			if globMatch(v, value) {
+				return true
+			}
+		}
+		return false
+	case opNotIn:
+		if !ok {
+			return true
+		}
+		for _, v := range c.values {
+			if globMatch(v, value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func globMatch(pattern, value string) bool {
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// ListByLabelSelector returns agents whose labels satisfy expr.
+func (s *State) ListByLabelSelector(expr string) ([]*Agent, error) {
+	sel, err := ParseSelector(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var agents []*Agent
+	for _, agent := range s.Agents {
+		if sel.Matches(agent.Labels) {
+			agents = append(agents, agent)
+		}
+	}
+	return agents, nil
+}
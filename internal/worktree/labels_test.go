@@ -0,0 +1,43 @@
+package worktree
+
+import "testing"
+
+func TestParseSelectorMultiValueIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		labels map[string]string
+		want   bool
+	}{
+		{
+			name:   "in clause matches one of several comma-separated values",
+			expr:   "lang in (go,rust)",
+			labels: map[string]string{"lang": "rust"},
+			want:   true,
+		},
+		{
+			name:   "in clause rejects a value outside the set",
+			expr:   "lang in (go,rust)",
+			labels: map[string]string{"lang": "python"},
+			want:   false,
+		},
+		{
+			name:   "notin clause combined with a following equality clause",
+			expr:   "lang notin (go,rust),risk=low",
+			labels: map[string]string{"lang": "python", "risk": "low"},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, err := ParseSelector(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) returned error: %v", tt.expr, err)
+			}
+			if got := sel.Matches(tt.labels); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
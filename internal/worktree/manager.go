@@ -5,7 +5,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -16,6 +15,43 @@ import (
 type Manager struct {
 	repoRoot string
 	state    *State
+	vcs      VCS
+
+	// pendingConflicts accumulates per-agent conflict-resolution counts
+	// between HasConflicts/ResolveConflict calls and the eventual Merge,
+	// which folds them into a MergeRecord.
+	pendingConflicts map[string]*conflictCounts
+}
+
+// conflictCounts tracks how conflicts were resolved for an in-progress
+// merge, before it lands in MergeHistory.
+type conflictCounts struct {
+	resolved  int
+	escalated int
+}
+
+func (m *Manager) recordConflictResolved(id string) {
+	if m.pendingConflicts == nil {
+		m.pendingConflicts = make(map[string]*conflictCounts)
+	}
+	c, ok := m.pendingConflicts[id]
+	if !ok {
+		c = &conflictCounts{}
+		m.pendingConflicts[id] = c
+	}
+	c.resolved++
+}
+
+func (m *Manager) recordConflictEscalated(id string) {
+	if m.pendingConflicts == nil {
+		m.pendingConflicts = make(map[string]*conflictCounts)
+	}
+	c, ok := m.pendingConflicts[id]
+	if !ok {
+		c = &conflictCounts{}
+		m.pendingConflicts[id] = c
+	}
+	c.escalated++
 }
 
 // NewManager creates a new worktree manager
@@ -31,9 +67,15 @@ func NewManager(repoRoot string) (*Manager, error) {
 	}
 	state.RepoRoot = repoRoot
 
+	vcs, err := newVCS(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init VCS backend: %w", err)
+	}
+
 	return &Manager{
 		repoRoot: repoRoot,
 		state:    state,
+		vcs:      vcs,
 	}, nil
 }
 
@@ -62,33 +104,22 @@ func slugify(s string) string {
 	return s
 }
 
-// git runs a git command and returns output
-func (m *Manager) git(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = m.repoRoot
-	output, err := cmd.CombinedOutput()
-	return strings.TrimSpace(string(output)), err
-}
-
 // GetCurrentBranch returns the current branch name
 func (m *Manager) GetCurrentBranch() (string, error) {
-	return m.git("rev-parse", "--abbrev-ref", "HEAD")
+	return m.vcs.CurrentBranch()
 }
 
 // GetCurrentCommit returns the current commit SHA
 func (m *Manager) GetCurrentCommit() (string, error) {
-	return m.git("rev-parse", "HEAD")
+	return m.vcs.CurrentCommit()
 }
 
-// CreateWorktree creates a new worktree for an agent
-func (m *Manager) CreateWorktree(task string) (*Agent, error) {
-	// Generate IDs
-	id := generateID()
-	slug := slugify(task)
-	branch := fmt.Sprintf("cwt/%s/%s", id, slug)
-	worktreePath := filepath.Join(m.repoRoot, m.state.WorktreeDir, id)
-
-	// Get current branch and commit for base
+// CreateWorktree creates a new worktree for an agent, based on the
+// repository's currently checked-out branch. labels may be nil; they're
+// used for later filtering/routing via ListByLabelSelector. adapterName
+// records which agent.Adapter the caller will spawn in this worktree
+// ("" means the default adapter).
+func (m *Manager) CreateWorktree(task string, labels map[string]string, adapterName string) (*Agent, error) {
 	baseBranch, err := m.GetCurrentBranch()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current branch: %w", err)
@@ -99,29 +130,43 @@ func (m *Manager) CreateWorktree(task string) (*Agent, error) {
 		return nil, fmt.Errorf("failed to get current commit: %w", err)
 	}
 
+	return m.createWorktreeFrom(task, baseBranch, baseCommit, labels, adapterName)
+}
+
+// createWorktreeFrom is CreateWorktree generalized to an explicit base
+// branch/commit, so ApplyManifest can branch a task off another task's
+// worktree HEAD instead of the repo's checked-out HEAD.
+func (m *Manager) createWorktreeFrom(task, baseBranch, baseCommit string, labels map[string]string, adapterName string) (*Agent, error) {
+	id := generateID()
+	slug := slugify(task)
+	branch := fmt.Sprintf("cwt/%s/%s", id, slug)
+	worktreePath := filepath.Join(m.repoRoot, m.state.WorktreeDir, id)
+
 	// Create worktree with new branch
-	if _, err := m.git("worktree", "add", "-b", branch, worktreePath); err != nil {
+	if err := m.vcs.CreateWorktree(worktreePath, branch, baseCommit); err != nil {
 		return nil, fmt.Errorf("failed to create worktree: %w", err)
 	}
 
 	// Create agent record
 	agent := &Agent{
-		ID:         id,
-		Branch:     branch,
-		Worktree:   worktreePath,
-		Task:       task,
-		Status:     StatusRunning,
-		BaseBranch: baseBranch,
-		BaseCommit: baseCommit,
-		CreatedAt:  time.Now(),
+		ID:          id,
+		Branch:      branch,
+		Worktree:    worktreePath,
+		Task:        task,
+		Status:      StatusRunning,
+		BaseBranch:  baseBranch,
+		BaseCommit:  baseCommit,
+		CreatedAt:   time.Now(),
+		Labels:      labels,
+		AdapterName: adapterName,
 	}
 
 	// Save state
 	m.state.AddAgent(agent)
 	if err := m.state.Save(); err != nil {
 		// Try to clean up worktree on save failure
-		m.git("worktree", "remove", worktreePath)
-		m.git("branch", "-D", branch)
+		m.vcs.RemoveWorktree(worktreePath, true)
+		m.vcs.DeleteBranch(branch, true)
 		return nil, fmt.Errorf("failed to save state: %w", err)
 	}
 
@@ -136,15 +181,15 @@ func (m *Manager) RemoveWorktree(id string) error {
 	}
 
 	// Remove worktree
-	if _, err := m.git("worktree", "remove", agent.Worktree); err != nil {
+	if err := m.vcs.RemoveWorktree(agent.Worktree, false); err != nil {
 		// Try force remove if normal fails
-		m.git("worktree", "remove", "--force", agent.Worktree)
+		m.vcs.RemoveWorktree(agent.Worktree, true)
 	}
 
 	// Delete branch
-	if _, err := m.git("branch", "-d", agent.Branch); err != nil {
+	if err := m.vcs.DeleteBranch(agent.Branch, false); err != nil {
 		// Force delete if not merged
-		m.git("branch", "-D", agent.Branch)
+		m.vcs.DeleteBranch(agent.Branch, true)
 	}
 
 	// Update state
@@ -190,7 +235,7 @@ func (m *Manager) GetDiff(id string) (string, error) {
 		return "", fmt.Errorf("agent %s not found", id)
 	}
 
-	return m.git("diff", agent.BaseBranch+"..."+agent.Branch)
+	return m.vcs.Diff(agent.BaseBranch, agent.Branch)
 }
 
 // GetCommits returns commits on agent branch since diverging from base
@@ -200,28 +245,34 @@ func (m *Manager) GetCommits(id string) (string, error) {
 		return "", fmt.Errorf("agent %s not found", id)
 	}
 
-	return m.git("log", "--oneline", agent.BaseBranch+".."+agent.Branch)
+	return m.vcs.Log(agent.BaseBranch, agent.Branch)
 }
 
-// HasConflicts checks if merging would cause conflicts
-func (m *Manager) HasConflicts(id string) (bool, error) {
-	agent, ok := m.state.GetAgent(id)
-	if !ok {
-		return false, fmt.Errorf("agent %s not found", id)
+// Diff returns the diff between agent branch and base as bytes, for the
+// diff/log overlay view.
+func (m *Manager) Diff(id string) ([]byte, error) {
+	diff, err := m.GetDiff(id)
+	if err != nil {
+		return nil, err
 	}
+	return []byte(diff), nil
+}
 
-	// Get merge base
-	mergeBase, err := m.git("merge-base", agent.BaseBranch, agent.Branch)
+// Log returns the oneline commit log for agent branch since diverging from
+// base as bytes, for the diff/log overlay view.
+func (m *Manager) Log(id string) ([]byte, error) {
+	log, err := m.GetCommits(id)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-
-	// Check merge-tree for conflicts
-	output, _ := m.git("merge-tree", mergeBase, agent.BaseBranch, agent.Branch)
-	return strings.Contains(output, "<<<<<<"), nil
+	return []byte(log), nil
 }
 
-// Merge merges an agent's branch into the base branch
+// Merge merges an agent's branch into the base branch. If the merge
+// conflicts, it's left in progress in the main checkout (the same state
+// HasConflicts leaves behind) for AbortMerge to clean up; use HasConflicts
+// instead when conflicts need to be resolved rather than treated as a
+// failure.
 func (m *Manager) Merge(id string) error {
 	agent, ok := m.state.GetAgent(id)
 	if !ok {
@@ -233,20 +284,83 @@ func (m *Manager) Merge(id string) error {
 	m.state.Save()
 
 	// Checkout base branch
-	if _, err := m.git("checkout", agent.BaseBranch); err != nil {
+	if err := m.vcs.Checkout(agent.BaseBranch); err != nil {
 		return fmt.Errorf("failed to checkout base branch: %w", err)
 	}
 
 	// Merge
 	msg := fmt.Sprintf("Merge %s: %s", agent.ID, agent.Task)
-	if _, err := m.git("merge", "--no-ff", "-m", msg, agent.Branch); err != nil {
+	if err := m.vcs.Merge(agent.Branch, msg); err != nil {
 		return fmt.Errorf("merge failed: %w", err)
 	}
 
-	// Update status
+	return m.finishMerge(agent)
+}
+
+// finishMerge records a merge that has just completed (whether vcs.Merge
+// committed it cleanly, or ResolveMergeConflict staged its last
+// conflicted file and the caller committed it by hand) into MergeHistory
+// and marks the agent merged.
+func (m *Manager) finishMerge(agent *Agent) error {
+	mergeCommit, _ := m.vcs.CurrentCommit()
+
 	now := time.Now()
 	agent.Status = StatusMerged
 	agent.MergedAt = &now
+
+	record := MergeRecord{
+		AgentID:     agent.ID,
+		MergedAt:    now,
+		MergeCommit: mergeCommit,
+	}
+	if counts, ok := m.pendingConflicts[agent.ID]; ok {
+		record.ConflictsResolved = counts.resolved
+		record.ConflictsEscalated = counts.escalated
+		delete(m.pendingConflicts, agent.ID)
+	}
+	m.state.MergeHistory = append(m.state.MergeHistory, record)
+
+	return m.state.Save()
+}
+
+// MergeSelector merges every agent matching the label selector expr,
+// continuing past individual failures and returning one error per
+// failed agent ID.
+func (m *Manager) MergeSelector(expr string) map[string]error {
+	agents, err := m.state.ListByLabelSelector(expr)
+	if err != nil {
+		return map[string]error{"": err}
+	}
+
+	failures := make(map[string]error)
+	for _, agent := range agents {
+		if err := m.Merge(agent.ID); err != nil {
+			failures[agent.ID] = err
+		}
+	}
+	return failures
+}
+
+// maxSavedLabelSelectors caps the TUI filter box's persisted history;
+// older entries fall off the end once a newer one pushes past this.
+const maxSavedLabelSelectors = 10
+
+// SaveLabelSelector records expr as the most recently used label filter,
+// moving it to the front of State.LabelSelectors (deduplicating earlier
+// occurrences) and persisting the change, so the TUI's filter history
+// survives across restarts instead of resetting every session.
+func (m *Manager) SaveLabelSelector(expr string) error {
+	selectors := make([]string, 0, len(m.state.LabelSelectors)+1)
+	selectors = append(selectors, expr)
+	for _, s := range m.state.LabelSelectors {
+		if s != expr {
+			selectors = append(selectors, s)
+		}
+	}
+	if len(selectors) > maxSavedLabelSelectors {
+		selectors = selectors[:maxSavedLabelSelectors]
+	}
+	m.state.LabelSelectors = selectors
 	return m.state.Save()
 }
 
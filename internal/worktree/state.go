@@ -21,15 +21,19 @@ const (
 
 // Agent represents a worktree agent
 type Agent struct {
-	ID         string      `json:"id"`
-	Branch     string      `json:"branch"`
-	Worktree   string      `json:"worktree"`
-	Task       string      `json:"task"`
-	Status     AgentStatus `json:"status"`
-	BaseBranch string      `json:"baseBranch"`
-	BaseCommit string      `json:"baseCommit"`
-	CreatedAt  time.Time   `json:"createdAt"`
-	MergedAt   *time.Time  `json:"mergedAt,omitempty"`
+	ID         string            `json:"id"`
+	Branch     string            `json:"branch"`
+	Worktree   string            `json:"worktree"`
+	Task       string            `json:"task"`
+	Status     AgentStatus       `json:"status"`
+	BaseBranch string            `json:"baseBranch"`
+	BaseCommit string            `json:"baseCommit"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	MergedAt   *time.Time        `json:"mergedAt,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	// AdapterName is the agent.Adapter this agent's session was spawned
+	// with (e.g. "claude", "aider"); empty means the default adapter.
+	AdapterName string `json:"adapterName,omitempty"`
 }
 
 // State represents the persisted state of all agents
@@ -39,15 +43,19 @@ type State struct {
 	WorktreeDir  string            `json:"worktreeDir"`
 	Agents       map[string]*Agent `json:"agents"`
 	MergeHistory []MergeRecord     `json:"mergeHistory"`
+	// LabelSelectors is the TUI filter box's history, most-recently-used
+	// first, so a user's filter survives across sessions instead of
+	// resetting every time the TUI starts. See Manager.SaveLabelSelector.
+	LabelSelectors []string `json:"labelSelectors,omitempty"`
 }
 
 // MergeRecord tracks a completed merge
 type MergeRecord struct {
-	AgentID           string    `json:"agentId"`
-	MergedAt          time.Time `json:"mergedAt"`
-	MergeCommit       string    `json:"mergeCommit"`
-	ConflictsResolved int       `json:"conflictsResolved"`
-	ConflictsEscalated int      `json:"conflictsEscalated"`
+	AgentID            string    `json:"agentId"`
+	MergedAt           time.Time `json:"mergedAt"`
+	MergeCommit        string    `json:"mergeCommit"`
+	ConflictsResolved  int       `json:"conflictsResolved"`
+	ConflictsEscalated int       `json:"conflictsEscalated"`
 }
 
 // NewState creates a new empty state
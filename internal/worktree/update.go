@@ -0,0 +1,189 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UpdateStrategy selects how Manager.UpdateFromBase brings an agent's
+// branch up to date with its base branch.
+type UpdateStrategy string
+
+const (
+	UpdateMerge            UpdateStrategy = "merge"
+	UpdateRebase           UpdateStrategy = "rebase"
+	UpdateRebaseAutosquash UpdateStrategy = "rebase-autosquash"
+)
+
+// UpdateResult reports the outcome of UpdateFromBase. If Conflicts is
+// non-empty, the update stopped mid-way and the agent's worktree is left
+// in a conflicted merge/rebase state for ResolveConflict/AbortMerge to
+// handle, the same as a regular merge conflict.
+type UpdateResult struct {
+	Strategy  UpdateStrategy   `json:"strategy"`
+	HeadSHA   string           `json:"headSha"`
+	Replayed  []string         `json:"replayed"`
+	Conflicts []ConflictedFile `json:"conflicts,omitempty"`
+}
+
+// UpdateFromBase brings an agent's branch up to date with its base branch
+// using the given strategy. It operates entirely within the agent's own
+// worktree, so the user's main working tree is never touched.
+func (m *Manager) UpdateFromBase(id string, strategy UpdateStrategy) (*UpdateResult, error) {
+	agent, ok := m.state.GetAgent(id)
+	if !ok {
+		return nil, fmt.Errorf("agent %s not found", id)
+	}
+
+	// Refresh the base branch before updating against it. A missing
+	// remote isn't fatal for purely-local base branches.
+	runGitIn(m.repoRoot, "fetch", "origin", agent.BaseBranch)
+
+	beforeHead, err := runGitIn(agent.Worktree, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent head: %w", err)
+	}
+
+	var runErr error
+	switch strategy {
+	case UpdateMerge:
+		_, runErr = runGitIn(agent.Worktree, "merge", "--no-edit", agent.BaseBranch)
+	case UpdateRebase:
+		runErr = m.rebaseWithLFS(agent.Worktree, agent.BaseBranch, false)
+	case UpdateRebaseAutosquash:
+		runErr = m.rebaseWithLFS(agent.Worktree, agent.BaseBranch, true)
+	default:
+		return nil, fmt.Errorf("unknown update strategy %q", strategy)
+	}
+
+	result := &UpdateResult{Strategy: strategy}
+
+	if runErr != nil {
+		conflicts, collectErr := m.collectConflicts(agent.Worktree)
+		if collectErr == nil && len(conflicts) > 0 {
+			result.Conflicts = conflicts
+			return result, nil
+		}
+		m.abortUpdate(agent.Worktree, strategy)
+		return nil, fmt.Errorf("update failed: %w", runErr)
+	}
+
+	head, err := runGitIn(agent.Worktree, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new head: %w", err)
+	}
+	result.HeadSHA = head
+
+	replayed, _ := runGitIn(agent.Worktree, "log", "--oneline", beforeHead+".."+head)
+	for _, line := range strings.Split(replayed, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			result.Replayed = append(result.Replayed, line)
+		}
+	}
+
+	return result, nil
+}
+
+// rebaseWithLFS rebases onto, continuing through any conflict that's
+// purely in Git LFS pointer files by re-smudging them from .gitattributes
+// before each "rebase --continue", so large-file pointers never get
+// three-way merged into garbage. A conflict outside LFS-tracked paths is
+// left in place for the caller to report.
+func (m *Manager) rebaseWithLFS(worktree, onto string, autosquash bool) error {
+	args := []string{"rebase"}
+	if autosquash {
+		args = append(args, "--autosquash", "--autostash")
+	}
+	args = append(args, onto)
+
+	_, err := runGitIn(worktree, args...)
+	return m.driveRebaseToCompletion(worktree, err)
+}
+
+// driveRebaseToCompletion resolves LFS-pointer conflicts left by a rebase
+// step and keeps calling "rebase --continue" until the rebase finishes or
+// fails for good. err is the error from the rebase step that needs
+// continuing (the initial "rebase" call, or a prior "rebase --continue").
+//
+// restoreLFSPointers can clear the conflict markers but still leave
+// "rebase --continue" failing, e.g. when the resolved pointer content
+// turns out identical to what's already in the tree and the replayed
+// commit becomes empty ("nothing to commit"), or when continue fails for
+// an unrelated reason (disk full, a rejected hook, a wedged index lock).
+// hasUnresolvedConflicts doesn't catch any of that, so each continue
+// attempt is checked against the worktree HEAD beforehand; if HEAD didn't
+// move, the rebase isn't making progress and we bail out with an error
+// instead of retrying forever.
+func (m *Manager) driveRebaseToCompletion(worktree string, err error) error {
+	for err != nil {
+		if lfsErr := restoreLFSPointers(worktree); lfsErr != nil {
+			return lfsErr
+		}
+		if hasUnresolvedConflicts(worktree) {
+			return err
+		}
+		preHead, _ := runGitIn(worktree, "rev-parse", "HEAD")
+		_, err = runGitIn(worktree, "rebase", "--continue")
+		if err == nil {
+			break
+		}
+		if postHead, headErr := runGitIn(worktree, "rev-parse", "HEAD"); headErr == nil && postHead == preHead {
+			return fmt.Errorf("rebase --continue made no progress past %s, aborting instead of retrying forever: %w", preHead, err)
+		}
+	}
+	return nil
+}
+
+// restoreLFSPointers re-checks out the "theirs" side of any Git
+// LFS–tracked path in the current conflict, so the pointer file isn't left
+// as a merged blob of two pointers.
+func restoreLFSPointers(worktree string) error {
+	attrs, err := os.ReadFile(filepath.Join(worktree, ".gitattributes"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(attrs), "filter=lfs") {
+		return nil
+	}
+
+	unmerged, err := runGitIn(worktree, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return err
+	}
+	for _, path := range strings.Split(unmerged, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" || !lfsTracked(worktree, path) {
+			continue
+		}
+		if _, err := runGitIn(worktree, "checkout", "--theirs", "--", path); err != nil {
+			return err
+		}
+		if _, err := runGitIn(worktree, "add", "--", path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func lfsTracked(worktree, path string) bool {
+	out, err := runGitIn(worktree, "check-attr", "filter", "--", path)
+	return err == nil && strings.Contains(out, "filter: lfs")
+}
+
+func hasUnresolvedConflicts(worktree string) bool {
+	out, _ := runGitIn(worktree, "diff", "--name-only", "--diff-filter=U")
+	return strings.TrimSpace(out) != ""
+}
+
+func (m *Manager) abortUpdate(worktree string, strategy UpdateStrategy) {
+	if strategy == UpdateMerge {
+		runGitIn(worktree, "merge", "--abort")
+		return
+	}
+	runGitIn(worktree, "rebase", "--abort")
+}
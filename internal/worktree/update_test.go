@@ -0,0 +1,103 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeAndCommit writes path=content in dir and commits it.
+func writeAndCommit(t *testing.T, dir, path, content, msg string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	cmd := exec.Command("git", "add", "-A")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+	cmd = exec.Command("git", "commit", "-q", "-m", msg)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+}
+
+// TestDriveRebaseToCompletionBailsOutWhenStuck reproduces a
+// "rebase --continue" that keeps failing for a reason other than
+// unresolved conflicts: the conflict on the LFS-tracked path is already
+// resolved (restoreLFSPointers is a no-op, hasUnresolvedConflicts is
+// false), but continue itself can't commit because the worktree's index
+// is wedged. Before the progress guard, driveRebaseToCompletion looped on
+// that forever instead of surfacing the error.
+func TestDriveRebaseToCompletionBailsOutWhenStuck(t *testing.T) {
+	repoRoot := newTestRepo(t)
+
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoRoot
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	writeAndCommit(t, repoRoot, ".gitattributes", "large.bin filter=lfs diff=lfs merge=lfs -text\n", "track lfs")
+	writeAndCommit(t, repoRoot, "large.bin", "pointer-v1\n", "seed large.bin")
+
+	run("branch", "agent")
+	run("checkout", "-q", "agent")
+	writeAndCommit(t, repoRoot, "large.bin", "pointer-agent\n", "agent updates pointer")
+	run("checkout", "-q", "main")
+	writeAndCommit(t, repoRoot, "large.bin", "pointer-main\n", "main updates pointer")
+
+	worktreePath := t.TempDir()
+	run("worktree", "add", "-q", worktreePath, "agent")
+
+	rebaseCmd := exec.Command("git", "rebase", "main")
+	rebaseCmd.Dir = worktreePath
+	if out, err := rebaseCmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected git rebase to conflict on large.bin, it succeeded:\n%s", out)
+	}
+
+	// Resolve the conflict exactly as rebaseWithLFS's first iteration
+	// would, then confirm there's nothing left for hasUnresolvedConflicts
+	// to catch.
+	if err := restoreLFSPointers(worktreePath); err != nil {
+		t.Fatalf("restoreLFSPointers: %v", err)
+	}
+	if hasUnresolvedConflicts(worktreePath) {
+		t.Fatal("conflict still unresolved after restoreLFSPointers")
+	}
+
+	lockPath, err := runGitIn(worktreePath, "rev-parse", "--git-path", "index.lock")
+	if err != nil {
+		t.Fatalf("rev-parse --git-path index.lock: %v", err)
+	}
+	if !filepath.IsAbs(lockPath) {
+		lockPath = filepath.Join(worktreePath, lockPath)
+	}
+	if err := os.WriteFile(lockPath, nil, 0o644); err != nil {
+		t.Fatalf("create index.lock: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(lockPath) })
+
+	m := &Manager{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- m.driveRebaseToCompletion(worktreePath, errors.New("conflict"))
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("driveRebaseToCompletion succeeded despite a wedged index, expected an error")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("driveRebaseToCompletion did not return within 10s; it's spinning on rebase --continue again")
+	}
+}
@@ -0,0 +1,50 @@
+package worktree
+
+// VCS abstracts the version-control operations Manager needs so the
+// shell-based implementation can be swapped for an embedded backend without
+// touching any of the orchestration logic above it.
+//
+// Diff, Log, and Status are still plain strings on both backends, not the
+// typed per-file/per-commit results go-git's plumbing objects could give
+// gogit. Callers that want structured data (e.g. a future diff viewer)
+// currently have to parse this package's string output themselves; that's
+// scope this interface narrowed rather than dropped.
+type VCS interface {
+	// CurrentBranch returns the branch checked out in the main working copy.
+	CurrentBranch() (string, error)
+	// CurrentCommit returns the commit SHA checked out in the main working copy.
+	CurrentCommit() (string, error)
+
+	// CreateWorktree adds a new worktree at path on a new branch starting at
+	// baseCommit.
+	CreateWorktree(path, branch, baseCommit string) error
+	// RemoveWorktree removes the worktree at path. If force is true, removal
+	// proceeds even if the worktree has local modifications.
+	RemoveWorktree(path string, force bool) error
+	// DeleteBranch deletes branch. If force is true, deletion proceeds even
+	// if the branch isn't fully merged.
+	DeleteBranch(branch string, force bool) error
+
+	// Diff returns the diff between baseBranch and branch (three-dot, i.e.
+	// relative to their merge base).
+	Diff(baseBranch, branch string) (string, error)
+	// Log returns the commits reachable from branch but not baseBranch.
+	Log(baseBranch, branch string) (string, error)
+	// MergeBase returns the best common ancestor of a and b.
+	MergeBase(a, b string) (string, error)
+	// MergeTree performs a virtual merge of ours and theirs rooted at base
+	// and returns the raw tree/conflict output, without touching the
+	// working copy.
+	MergeTree(base, ours, theirs string) (string, error)
+
+	// Checkout switches the main working copy to branch.
+	Checkout(branch string) error
+	// Merge merges branch into the currently checked-out branch, creating a
+	// merge commit with the given message.
+	Merge(branch, message string) error
+	// Rebase replays the commits on branch onto the tip of onto.
+	Rebase(branch, onto string) error
+
+	// Status reports the working-tree status of the main working copy.
+	Status() (string, error)
+}
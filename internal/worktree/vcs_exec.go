@@ -0,0 +1,109 @@
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// execGit implements VCS by shelling out to the system git binary. It is
+// the default backend: no extra dependencies, and it behaves identically to
+// cwt's original hand-rolled git calls.
+type execGit struct {
+	repoRoot string
+}
+
+func newExecGit(repoRoot string) *execGit {
+	return &execGit{repoRoot: repoRoot}
+}
+
+// run executes a git subcommand in g.repoRoot and returns its combined,
+// trimmed output.
+func (g *execGit) run(args ...string) (string, error) {
+	return runGitIn(g.repoRoot, args...)
+}
+
+// runGitIn runs git in an arbitrary directory, for the handful of
+// operations (conflict detection/resolution, UpdateFromBase, manifest base
+// resolution) that must target an agent's own worktree rather than the main
+// repo root the VCS interface is bound to. LC_ALL=C pins git's own messages
+// to the C locale so downstream string parsing (conflict markers,
+// merge/rebase errors, status codes) isn't broken by a user's localized
+// LANG.
+func runGitIn(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (g *execGit) CurrentBranch() (string, error) {
+	return g.run("rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func (g *execGit) CurrentCommit() (string, error) {
+	return g.run("rev-parse", "HEAD")
+}
+
+func (g *execGit) CreateWorktree(path, branch, baseCommit string) error {
+	_, err := g.run("worktree", "add", "-b", branch, path, baseCommit)
+	return err
+}
+
+func (g *execGit) RemoveWorktree(path string, force bool) error {
+	args := []string{"worktree", "remove"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := g.run(append(args, path)...)
+	return err
+}
+
+func (g *execGit) DeleteBranch(branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := g.run("branch", flag, branch)
+	return err
+}
+
+func (g *execGit) Diff(baseBranch, branch string) (string, error) {
+	return g.run("diff", baseBranch+"..."+branch)
+}
+
+func (g *execGit) Log(baseBranch, branch string) (string, error) {
+	return g.run("log", "--oneline", baseBranch+".."+branch)
+}
+
+func (g *execGit) MergeBase(a, b string) (string, error) {
+	return g.run("merge-base", a, b)
+}
+
+func (g *execGit) MergeTree(base, ours, theirs string) (string, error) {
+	output, _ := g.run("merge-tree", base, ours, theirs)
+	return output, nil
+}
+
+func (g *execGit) Checkout(branch string) error {
+	_, err := g.run("checkout", branch)
+	return err
+}
+
+func (g *execGit) Merge(branch, message string) error {
+	_, err := g.run("merge", "--no-ff", "-m", message, branch)
+	return err
+}
+
+func (g *execGit) Rebase(branch, onto string) error {
+	if _, err := g.run("checkout", branch); err != nil {
+		return err
+	}
+	_, err := g.run("rebase", onto)
+	return err
+}
+
+func (g *execGit) Status() (string, error) {
+	return g.run("status", "--porcelain")
+}
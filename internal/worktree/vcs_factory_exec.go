@@ -0,0 +1,10 @@
+//go:build !gogit
+
+package worktree
+
+// newVCS returns the default VCS backend, which shells out to the system
+// git binary and has no extra dependencies. Build with -tags gogit to use
+// the embedded go-git backend instead.
+func newVCS(repoRoot string) (VCS, error) {
+	return newExecGit(repoRoot), nil
+}
@@ -0,0 +1,9 @@
+//go:build gogit
+
+package worktree
+
+// newVCS returns the go-git-backed VCS implementation. Built only when
+// compiled with -tags gogit.
+func newVCS(repoRoot string) (VCS, error) {
+	return newGoGit(repoRoot)
+}
@@ -0,0 +1,221 @@
+//go:build gogit
+
+package worktree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// goGit implements VCS on top of an embedded go-git repository, opened once
+// and reused across calls. CurrentBranch/CurrentCommit/MergeBase/Log walk
+// go-git's own plumbing objects directly instead of parsing `git` stdout;
+// Diff/Status build their result from go-git's typed Patch/Status objects,
+// though the VCS interface itself still hands callers back a string (see
+// vcs.go) so both backends stay interchangeable.
+//
+// go-git v5 has no native "worktree add"/"merge"/"merge-tree"/"rebase"
+// support, so those still shell out to the system git binary. CreateWorktree
+// additionally updates submodules after the shell adds the worktree, since
+// `git worktree add` alone leaves submodules uninitialized in the new
+// checkout.
+type goGit struct {
+	shell *execGit
+	repo  *git.Repository
+}
+
+func newGoGit(repoRoot string) (*goGit, error) {
+	repo, err := git.PlainOpen(repoRoot)
+	if err != nil {
+		return nil, fmt.Errorf("open repo with go-git: %w", err)
+	}
+	return &goGit{shell: newExecGit(repoRoot), repo: repo}, nil
+}
+
+func (g *goGit) resolve(ref string) (*object.Commit, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %q: %w", ref, err)
+	}
+	return g.repo.CommitObject(*hash)
+}
+
+func (g *goGit) CurrentBranch() (string, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Name().Short(), nil
+}
+
+func (g *goGit) CurrentCommit() (string, error) {
+	head, err := g.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+func (g *goGit) CreateWorktree(path, branch, baseCommit string) error {
+	if err := g.shell.CreateWorktree(path, branch, baseCommit); err != nil {
+		return err
+	}
+	return g.initSubmodules(path)
+}
+
+// initSubmodules runs `git submodule update --init --recursive` in the new
+// worktree if the checked-out tree has a .gitmodules file. `git worktree
+// add` populates the worktree from the superproject's tree but leaves any
+// submodules uninitialized, so without this a submodule-using repo would
+// silently hand back an incomplete checkout.
+func (g *goGit) initSubmodules(worktreePath string) error {
+	if _, err := os.Stat(filepath.Join(worktreePath, ".gitmodules")); os.IsNotExist(err) {
+		return nil
+	}
+	worktreeGit := newExecGit(worktreePath)
+	_, err := worktreeGit.run("submodule", "update", "--init", "--recursive")
+	return err
+}
+
+func (g *goGit) RemoveWorktree(path string, force bool) error {
+	return g.shell.RemoveWorktree(path, force)
+}
+
+func (g *goGit) DeleteBranch(branch string, force bool) error {
+	if !force {
+		// go-git can't tell us whether branch is merged, so defer to the
+		// shell backend for the safety check "-d" provides.
+		return g.shell.DeleteBranch(branch, force)
+	}
+	return g.repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(branch))
+}
+
+func (g *goGit) MergeBase(a, b string) (string, error) {
+	commitA, err := g.resolve(a)
+	if err != nil {
+		return "", err
+	}
+	commitB, err := g.resolve(b)
+	if err != nil {
+		return "", err
+	}
+	bases, err := commitA.MergeBase(commitB)
+	if err != nil {
+		return "", err
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no merge base between %q and %q", a, b)
+	}
+	return bases[0].Hash.String(), nil
+}
+
+func (g *goGit) Diff(baseBranch, branch string) (string, error) {
+	mergeBase, err := g.MergeBase(baseBranch, branch)
+	if err != nil {
+		return "", err
+	}
+	base, err := g.resolve(mergeBase)
+	if err != nil {
+		return "", err
+	}
+	head, err := g.resolve(branch)
+	if err != nil {
+		return "", err
+	}
+	patch, err := base.Patch(head)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := patch.Encode(&buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (g *goGit) Log(baseBranch, branch string) (string, error) {
+	mergeBase, err := g.MergeBase(baseBranch, branch)
+	if err != nil {
+		return "", err
+	}
+	head, err := g.resolve(branch)
+	if err != nil {
+		return "", err
+	}
+
+	iter, err := g.repo.Log(&git.LogOptions{From: head.Hash})
+	if err != nil {
+		return "", err
+	}
+	defer iter.Close()
+
+	var buf bytes.Buffer
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash.String() == mergeBase {
+			return object.ErrEntryNotFound // stop iteration
+		}
+		fmt.Fprintf(&buf, "%s %s\n", c.Hash.String()[:7], firstLine(c.Message))
+		return nil
+	})
+	if err != nil && err != object.ErrEntryNotFound {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func firstLine(s string) string {
+	for i, r := range s {
+		if r == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func (g *goGit) MergeTree(base, ours, theirs string) (string, error) {
+	// go-git has no merge-tree equivalent; fall back to the shell backend.
+	return g.shell.MergeTree(base, ours, theirs)
+}
+
+func (g *goGit) Checkout(branch string) error {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&git.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branch),
+	})
+}
+
+func (g *goGit) Merge(branch, message string) error {
+	// go-git has no native merge implementation as of v5.x; fall back to
+	// the shell backend, which already handles conflicts and merge commits.
+	return g.shell.Merge(branch, message)
+}
+
+func (g *goGit) Rebase(branch, onto string) error {
+	// go-git has no native rebase implementation; fall back to the shell
+	// backend.
+	return g.shell.Rebase(branch, onto)
+}
+
+func (g *goGit) Status() (string, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+	if status.IsClean() {
+		return "", nil
+	}
+	return status.String(), nil
+}